@@ -1,14 +1,22 @@
 package tgapimanager
 
 import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"path"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -23,13 +31,115 @@ type BotAPI struct {
 	Debug  bool   `json:"debug"`
 	Buffer int    `json:"buffer"`
 
-	Self            User       `json:"-"`
-	Client          HTTPClient `json:"-"`
+	Self        User        `json:"-"`
+	Client      HTTPClient  `json:"-"`
+	RetryPolicy RetryPolicy `json:"-"`
+
+	// DefaultParseMode is used for the text or caption of a request that has
+	// neither a ParseMode nor entities of its own.
+	DefaultParseMode string `json:"-"`
+
+	// UpdatesRetryInterval is how long to wait before fetching updates
+	// again after it failed. Zero means 3 seconds.
+	UpdatesRetryInterval time.Duration `json:"-"`
+
+	// SecretToken, if set, must be sent with every update received by
+	// HandleUpdate. It is the secret token set with WebhookConfig.
+	SecretToken string `json:"-"`
+
+	// OnPanic, if set, is called with the update and the recovered value
+	// when a handler passed to Poll panics, after the panic is logged.
+	OnPanic func(update Update, recovered interface{}) `json:"-"`
+
 	shutdownChannel chan interface{}
+	stopOnce        sync.Once
+
+	apiEndpoint  string
+	fileEndpoint string
+
+	lastMessagesOnce sync.Once
+	lastMessages     *lruCache[int64, int]
+
+	chatActionsMu sync.Mutex
+	chatActions   map[chatAction]time.Time
+
+	fileIDsOnce sync.Once
+	fileIDs     *lruCache[string, string]
+
+	log BotLogger
+}
+
+// RetryPolicy controls which failed requests are retried. Uploads are never
+// retried, as their files can't be read again.
+//
+// The zero value is the default policy.
+type RetryPolicy struct {
+	// DisableServerErrorRetry stops a request that failed with a 5xx status
+	// from being retried. By default it is retried once, straight away, as
+	// these errors are usually transient.
+	DisableServerErrorRetry bool
+
+	// RetryOnFloodWait retries a request that hit flood control, once the
+	// time Telegram asks to wait in ResponseParameters.RetryAfter has passed.
+	// The wait is cut short when the request's context is done.
+	RetryOnFloodWait bool
+	// MaxFloodRetries caps how many times a request is retried after flood
+	// control, so a bot that keeps hitting the limit doesn't retry forever.
+	// Zero means defaultMaxFloodRetries.
+	MaxFloodRetries int
+}
+
+// defaultMaxFloodRetries is the number of flood control retries used when
+// RetryPolicy.MaxFloodRetries isn't set.
+const defaultMaxFloodRetries = 3
 
-	apiEndpoint string
+// retryServerError returns if a request that failed with err should be
+// retried straight away.
+func (p RetryPolicy) retryServerError(err error) bool {
+	if p.DisableServerErrorRetry {
+		return false
+	}
+
+	var apiErr *Error
+
+	return errors.As(err, &apiErr) && apiErr.HTTPStatus >= http.StatusInternalServerError
 }
 
+// floodWait returns how long to wait before retrying a request that failed
+// with err after retries earlier flood control retries, or false if it
+// shouldn't be retried.
+func (p RetryPolicy) floodWait(err error, retries int) (time.Duration, bool) {
+	if !p.RetryOnFloodWait {
+		return 0, false
+	}
+
+	maxRetries := p.MaxFloodRetries
+	if maxRetries == 0 {
+		maxRetries = defaultMaxFloodRetries
+	}
+	if retries >= maxRetries {
+		return 0, false
+	}
+
+	apiErr, ok := AsAPIError(err)
+	if !ok || !apiErr.IsFloodError() || apiErr.RetryAfter <= 0 {
+		return 0, false
+	}
+
+	return apiErr.RetryAfterDuration(), true
+}
+
+// chatAction identifies an action shown in a chat.
+type chatAction struct {
+	chatID int64
+	action string
+}
+
+// chatActionInterval is how long a chat action is shown after it is sent.
+// Telegram shows an action for 5 seconds, this leaves time to send it again
+// before it disappears.
+const chatActionInterval = 4 * time.Second
+
 // NewBotAPI creates a new BotAPI instance.
 //
 // It requires a token, provided by @BotFather on Telegram.
@@ -50,17 +160,134 @@ func NewBotAPIWithAPIEndpoint(token, apiEndpoint string) (*BotAPI, error) {
 //
 // It requires a token, provided by @BotFather on Telegram and API endpoint.
 func NewBotAPIWithClient(token, apiEndpoint string, client HTTPClient) (*BotAPI, error) {
+	return NewBotAPIWithOptions(token, WithAPIEndpoint(apiEndpoint), WithClient(client))
+}
+
+// BotOption configures a BotAPI created with NewBotAPIWithOptions.
+type BotOption func(*botOptions)
+
+type botOptions struct {
+	apiEndpoint       string
+	client            HTTPClient
+	validationTimeout time.Duration
+
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+}
+
+// WithAPIEndpoint sets the Bot API endpoint, formatted like APIEndpoint.
+func WithAPIEndpoint(apiEndpoint string) BotOption {
+	return func(o *botOptions) {
+		o.apiEndpoint = apiEndpoint
+	}
+}
+
+// WithClient sets the client used for all requests.
+func WithClient(client HTTPClient) BotOption {
+	return func(o *botOptions) {
+		o.client = client
+	}
+}
+
+// WithValidationTimeout limits how long the getMe request used to validate
+// the token may take. By default there is no limit.
+func WithValidationTimeout(timeout time.Duration) BotOption {
+	return func(o *botOptions) {
+		o.validationTimeout = timeout
+	}
+}
+
+// WithMaxIdleConnsPerHost sets how many idle connections to the Bot API are
+// kept open for reuse.
+//
+// All requests go to a single host, and Go keeps only 2 idle connections per
+// host by default, so a bot sending many messages concurrently keeps opening
+// new TLS connections. For a bot sending thousands of messages per minute, a
+// value around the number of concurrent senders, such as 100, is sensible.
+//
+// It can't be combined with WithClient.
+func WithMaxIdleConnsPerHost(n int) BotOption {
+	return func(o *botOptions) {
+		o.maxIdleConnsPerHost = n
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle connection to the Bot API is
+// kept open. Go's default of 90 seconds suits most bots; a bot that sends in
+// bursts further apart may want a longer timeout.
+//
+// It can't be combined with WithClient.
+func WithIdleConnTimeout(d time.Duration) BotOption {
+	return func(o *botOptions) {
+		o.idleConnTimeout = d
+	}
+}
+
+// transport builds a transport with the connection tuning from the options,
+// or returns nil if there is none.
+func (o botOptions) transport() *http.Transport {
+	if o.maxIdleConnsPerHost == 0 && o.idleConnTimeout == 0 {
+		return nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if o.maxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = o.maxIdleConnsPerHost
+		transport.MaxIdleConns = max(transport.MaxIdleConns, o.maxIdleConnsPerHost)
+	}
+	if o.idleConnTimeout > 0 {
+		transport.IdleConnTimeout = o.idleConnTimeout
+	}
+
+	return transport
+}
+
+// NewBotAPIWithOptions creates a new BotAPI instance configured by opts.
+//
+// It requires a token, provided by @BotFather on Telegram. Without options it
+// behaves like NewBotAPI.
+func NewBotAPIWithOptions(token string, opts ...BotOption) (*BotAPI, error) {
+	options := botOptions{
+		apiEndpoint: APIEndpoint,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	transport := options.transport()
+
+	client := options.client
+	if client == nil {
+		client = &http.Client{}
+		if transport != nil {
+			client = &http.Client{Transport: transport}
+		}
+	} else if transport != nil {
+		return nil, errors.New("connection options can't be combined with WithClient, configure the client's transport instead")
+	}
+
 	bot := &BotAPI{
 		Token:           token,
 		Client:          client,
 		Buffer:          100,
 		shutdownChannel: make(chan interface{}),
 
-		apiEndpoint: apiEndpoint,
+		apiEndpoint: options.apiEndpoint,
+	}
+
+	ctx := context.Background()
+	if options.validationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.validationTimeout)
+		defer cancel()
 	}
 
-	self, err := bot.GetMe()
+	self, err := bot.GetMeWithContext(ctx)
 	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("getMe did not respond within %s: %w", options.validationTimeout, err)
+		}
+
 		return nil, err
 	}
 
@@ -69,11 +296,37 @@ func NewBotAPIWithClient(token, apiEndpoint string, client HTTPClient) (*BotAPI,
 	return bot, nil
 }
 
+// SetLogger specifies the logger this bot should use, instead of the one set
+// for the package with SetLogger.
+func (bot *BotAPI) SetLogger(logger BotLogger) error {
+	if logger == nil {
+		return errors.New("logger is nil")
+	}
+	bot.log = logger
+	return nil
+}
+
+// logger returns the logger set for the bot, or the package logger.
+func (bot *BotAPI) logger() BotLogger {
+	if bot.log != nil {
+		return bot.log
+	}
+
+	return log
+}
+
 // SetAPIEndpoint changes the Telegram Bot API endpoint used by the instance.
 func (bot *BotAPI) SetAPIEndpoint(apiEndpoint string) {
 	bot.apiEndpoint = apiEndpoint
 }
 
+// SetFileEndpoint changes the endpoint used to download files, formatted like
+// FileEndpoint. It is needed when running against a local Bot API server.
+func (bot *BotAPI) SetFileEndpoint(fileEndpoint string) {
+	bot.fileEndpoint = fileEndpoint
+}
+
+// buildParams converts in to form values, one value per key.
 func buildParams(in Params) url.Values {
 	if in == nil {
 		return url.Values{}
@@ -90,15 +343,58 @@ func buildParams(in Params) url.Values {
 
 // MakeRequest makes a request to a specific endpoint with our token.
 func (bot *BotAPI) MakeRequest(endpoint string, params Params) (*APIResponse, error) {
+	return bot.MakeRequestWithContext(context.Background(), endpoint, params)
+}
+
+// MakeRequestWithContext is the same as MakeRequest, but the request is
+// cancelled when ctx is done.
+func (bot *BotAPI) MakeRequestWithContext(ctx context.Context, endpoint string, params Params) (*APIResponse, error) {
+	retriedServerError := false
+	floodRetries := 0
+
+	for {
+		resp, err := bot.makeRequest(ctx, endpoint, params)
+		if err == nil || ctx.Err() != nil {
+			return resp, err
+		}
+
+		if !retriedServerError && bot.RetryPolicy.retryServerError(err) {
+			retriedServerError = true
+
+			if bot.Debug {
+				bot.logger().Printf("Endpoint: %s, retrying after server error: %v\n", endpoint, err)
+			}
+
+			continue
+		}
+
+		wait, ok := bot.RetryPolicy.floodWait(err, floodRetries)
+		if !ok {
+			return resp, err
+		}
+		floodRetries++
+
+		bot.logger().Printf("Endpoint: %s, flood control exceeded, retrying in %s\n", endpoint, wait)
+
+		select {
+		case <-ctx.Done():
+			return resp, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// makeRequest makes a single request to endpoint, without any retries.
+func (bot *BotAPI) makeRequest(ctx context.Context, endpoint string, params Params) (*APIResponse, error) {
 	if bot.Debug {
-		log.Printf("Endpoint: %s, params: %v\n", endpoint, params)
+		bot.logger().Printf("Endpoint: %s, params: %v\n", endpoint, params)
 	}
 
 	method := fmt.Sprintf(bot.apiEndpoint, bot.Token, endpoint)
 
 	values := buildParams(params)
 
-	req, err := http.NewRequest("POST", method, strings.NewReader(values.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", method, strings.NewReader(values.Encode()))
 	if err != nil {
 		return &APIResponse{}, err
 	}
@@ -111,13 +407,17 @@ func (bot *BotAPI) MakeRequest(endpoint string, params Params) (*APIResponse, er
 	defer resp.Body.Close()
 
 	var apiResp APIResponse
-	bytes, err := bot.decodeAPIResponse(resp.Body, &apiResp)
+	var snippet bodySnippet
+	bytes, err := bot.decodeAPIResponse(io.TeeReader(resp.Body, &snippet), &apiResp)
 	if err != nil {
-		return &apiResp, err
+		if !isInvalidJSON(err) {
+			return &apiResp, err
+		}
+		return &apiResp, decodeError(resp, snippet)
 	}
 
 	if bot.Debug {
-		log.Printf("Endpoint: %s, response: %s\n", endpoint, string(bytes))
+		bot.logger().Printf("Endpoint: %s, response: %s\n", endpoint, string(bytes))
 	}
 
 	if !apiResp.Ok {
@@ -130,6 +430,7 @@ func (bot *BotAPI) MakeRequest(endpoint string, params Params) (*APIResponse, er
 		return &apiResp, &Error{
 			Code:               apiResp.ErrorCode,
 			Message:            apiResp.Description,
+			HTTPStatus:         resp.StatusCode,
 			ResponseParameters: parameters,
 		}
 	}
@@ -161,8 +462,62 @@ func (bot *BotAPI) decodeAPIResponse(responseBody io.Reader, resp *APIResponse)
 	return data, nil
 }
 
+// maxBodySnippet is how much of an undecodable response body is included in
+// the error.
+const maxBodySnippet = 256
+
+// bodySnippet keeps the start of a response body for error messages.
+type bodySnippet struct {
+	data      []byte
+	truncated bool
+}
+
+func (s *bodySnippet) Write(p []byte) (int, error) {
+	n := min(maxBodySnippet-len(s.data), len(p))
+	s.data = append(s.data, p[:n]...)
+	s.truncated = s.truncated || n < len(p)
+
+	return len(p), nil
+}
+
+func (s bodySnippet) String() string {
+	text := strings.TrimSpace(string(s.data))
+	if s.truncated {
+		text += "…"
+	}
+
+	return text
+}
+
+// isInvalidJSON returns true if err means a response body was read but isn't
+// an API response, rather than that reading it failed.
+func isInvalidJSON(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	return err == io.EOF || errors.As(err, &syntaxErr) || errors.As(err, &typeErr)
+}
+
+// decodeError reports a response body that couldn't be decoded.
+//
+// Such a body most likely didn't come from the Bot API at all, for example an
+// HTML error page from a proxy, so the error includes the HTTP status and the
+// start of the body instead of the JSON decoding error.
+func decodeError(resp *http.Response, body bodySnippet) error {
+	return &Error{
+		Message:    fmt.Sprintf("unexpected %q response with HTTP status %s: %s", resp.Header.Get("Content-Type"), resp.Status, body),
+		HTTPStatus: resp.StatusCode,
+	}
+}
+
 // UploadFiles makes a request to the API with files.
 func (bot *BotAPI) UploadFiles(endpoint string, params Params, files []RequestFile) (*APIResponse, error) {
+	return bot.UploadFilesWithContext(context.Background(), endpoint, params, files)
+}
+
+// UploadFilesWithContext is the same as UploadFiles, but the request is
+// cancelled when ctx is done.
+func (bot *BotAPI) UploadFilesWithContext(ctx context.Context, endpoint string, params Params, files []RequestFile) (*APIResponse, error) {
 	r, w := io.Pipe()
 	m := multipart.NewWriter(w)
 
@@ -216,12 +571,12 @@ func (bot *BotAPI) UploadFiles(endpoint string, params Params, files []RequestFi
 	}()
 
 	if bot.Debug {
-		log.Printf("Endpoint: %s, params: %v, with %d files\n", endpoint, params, len(files))
+		bot.logger().Printf("Endpoint: %s, params: %v, with %d files\n", endpoint, params, len(files))
 	}
 
 	method := fmt.Sprintf(bot.apiEndpoint, bot.Token, endpoint)
 
-	req, err := http.NewRequest("POST", method, r)
+	req, err := http.NewRequestWithContext(ctx, "POST", method, r)
 	if err != nil {
 		return nil, err
 	}
@@ -235,13 +590,17 @@ func (bot *BotAPI) UploadFiles(endpoint string, params Params, files []RequestFi
 	defer resp.Body.Close()
 
 	var apiResp APIResponse
-	bytes, err := bot.decodeAPIResponse(resp.Body, &apiResp)
+	var snippet bodySnippet
+	bytes, err := bot.decodeAPIResponse(io.TeeReader(resp.Body, &snippet), &apiResp)
 	if err != nil {
-		return &apiResp, err
+		if !isInvalidJSON(err) {
+			return &apiResp, err
+		}
+		return &apiResp, decodeError(resp, snippet)
 	}
 
 	if bot.Debug {
-		log.Printf("Endpoint: %s, response: %s\n", endpoint, string(bytes))
+		bot.logger().Printf("Endpoint: %s, response: %s\n", endpoint, string(bytes))
 	}
 
 	if !apiResp.Ok {
@@ -252,7 +611,9 @@ func (bot *BotAPI) UploadFiles(endpoint string, params Params, files []RequestFi
 		}
 
 		return &apiResp, &Error{
+			Code:               apiResp.ErrorCode,
 			Message:            apiResp.Description,
+			HTTPStatus:         resp.StatusCode,
 			ResponseParameters: parameters,
 		}
 	}
@@ -266,7 +627,13 @@ func (bot *BotAPI) UploadFiles(endpoint string, params Params, files []RequestFi
 // and so you may get this data from BotAPI.Self without the need for
 // another request.
 func (bot *BotAPI) GetMe() (User, error) {
-	resp, err := bot.MakeRequest("getMe", nil)
+	return bot.GetMeWithContext(context.Background())
+}
+
+// GetMeWithContext is the same as GetMe, but the request is cancelled when
+// ctx is done.
+func (bot *BotAPI) GetMeWithContext(ctx context.Context) (User, error) {
+	resp, err := bot.MakeRequestWithContext(ctx, "getMe", nil)
 	if err != nil {
 		return User{}, err
 	}
@@ -288,6 +655,16 @@ func hasFilesNeedingUpload(files []RequestFile) bool {
 
 // Request sends a Chattable to Telegram, and returns the APIResponse.
 func (bot *BotAPI) Request(c Chattable) (*APIResponse, error) {
+	return bot.RequestWithContext(context.Background(), c)
+}
+
+// RequestWithContext is the same as Request, but the request is cancelled
+// when ctx is done.
+func (bot *BotAPI) RequestWithContext(ctx context.Context, c Chattable) (*APIResponse, error) {
+	if bot.DefaultParseMode != "" {
+		c = withDefaultParseMode(c, bot.DefaultParseMode)
+	}
+
 	params, err := c.params()
 	if err != nil {
 		return nil, err
@@ -299,7 +676,7 @@ func (bot *BotAPI) Request(c Chattable) (*APIResponse, error) {
 		// If we have files that need to be uploaded, we should delegate the
 		// request to UploadFile.
 		if hasFilesNeedingUpload(files) {
-			return bot.UploadFiles(t.method(), params, files)
+			return bot.UploadFilesWithContext(ctx, t.method(), params, files)
 		}
 
 		// However, if there are no files to be uploaded, there's likely things
@@ -309,25 +686,297 @@ func (bot *BotAPI) Request(c Chattable) (*APIResponse, error) {
 		}
 	}
 
-	return bot.MakeRequest(c.method(), params)
+	return bot.MakeRequestWithContext(ctx, c.method(), params)
+}
+
+// RequestAndParse sends a Chattable to Telegram and decodes the result into
+// T. It is meant for methods without a dedicated wrapper, for example:
+//
+//	poll, err := RequestAndParse[Poll](bot, StopPollConfig{BaseEdit{ChatID: chatID, MessageID: messageID}})
+func RequestAndParse[T any](bot *BotAPI, c Chattable) (T, error) {
+	var result T
+
+	resp, err := bot.Request(c)
+	if err != nil {
+		return result, err
+	}
+
+	err = json.Unmarshal(resp.Result, &result)
+
+	return result, err
 }
 
 // Send will send a Chattable item to Telegram and provides the
 // returned Message.
 func (bot *BotAPI) Send(c Chattable) (Message, error) {
-	resp, err := bot.Request(c)
+	return RequestAndParse[Message](bot, c)
+}
+
+// CopyMessage copies a message without a link to the original message and
+// returns the ID of the sent message.
+func (bot *BotAPI) CopyMessage(config CopyMessageConfig) (MessageID, error) {
+	return RequestAndParse[MessageID](bot, config)
+}
+
+// SendPoll sends a poll and returns the sent message, which holds the poll in
+// message.Poll.
+//
+// Keep message.Poll.ID to match later poll and poll_answer updates. To stop
+// the poll, stopPoll needs the chat and message.MessageID rather than the
+// poll ID.
+func (bot *BotAPI) SendPoll(config SendPollConfig) (Message, error) {
+	return bot.Send(config)
+}
+
+// SendLongMessage sends config as several messages when its text is longer
+// than MaxMessageLength, and returns every message that was sent.
+//
+// The text is split on line breaks or spaces where possible and never inside
+// one of config.Entities; entities are moved to the message they end up in,
+// with their offsets adjusted. Only an entity longer than a whole message is
+// split, in which case each message gets its part of it. The reply is only
+// made by the first message and the reply markup is only attached to the
+// last one.
+func (bot *BotAPI) SendLongMessage(config MessageConfig) ([]Message, error) {
+	chunks := splitMessageText(config.Text, config.Entities, MaxMessageLength)
+	messages := make([]Message, 0, len(chunks))
+
+	for i, chunk := range chunks {
+		part := config
+		part.Text = chunk.text
+		part.Entities = chunk.entities
+
+		if i > 0 {
+			part.ReplyToMessageID = 0
+		}
+		if i < len(chunks)-1 {
+			part.ReplyMarkup = nil
+		}
+
+		message, err := bot.Send(part)
+		if err != nil {
+			return messages, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+// SendMediaGroup sends a media group and returns the resulting messages.
+func (bot *BotAPI) SendMediaGroup(config MediaGroupConfig) ([]Message, error) {
+	return RequestAndParse[[]Message](bot, config)
+}
+
+// SendPhotoCached sends a photo, uploading its content only the first time
+// it is sent.
+//
+// The content of file is hashed and the file ID Telegram returns for it is
+// remembered, so sending the same content again sends the file ID instead.
+// Only the most recently used file IDs are kept. If a remembered file ID is
+// rejected as invalid or expired, the photo is uploaded again; other errors
+// are returned as is. A FileID or FileURL is sent as is.
+func (bot *BotAPI) SendPhotoCached(chatID int64, file RequestFileData) (Message, error) {
+	if !file.NeedsUpload() {
+		return bot.Send(NewPhoto(chatID, file))
+	}
+
+	name, content, err := readUploadData(file)
 	if err != nil {
 		return Message{}, err
 	}
 
-	var message Message
-	err = json.Unmarshal(resp.Result, &message)
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	cache := bot.fileIDCache()
 
-	return message, err
+	if fileID, ok := cache.get(hash); ok {
+		message, err := bot.Send(NewPhoto(chatID, FileID(fileID)))
+
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || !isStaleFileID(apiErr) {
+			return message, err
+		}
+
+		cache.remove(hash)
+	}
+
+	message, err := bot.Send(NewPhoto(chatID, FileBytes{Name: name, Bytes: content}))
+	if err != nil {
+		return message, err
+	}
+
+	if len(message.Photo) > 0 {
+		cache.add(hash, message.Photo[len(message.Photo)-1].FileID)
+	}
+
+	return message, nil
+}
+
+// isStaleFileID returns true if err means Telegram no longer accepts a file
+// ID, so the file has to be uploaded again.
+func isStaleFileID(err *Error) bool {
+	return err.Code == http.StatusBadRequest &&
+		(strings.Contains(err.Message, "wrong file identifier") || strings.Contains(err.Message, "file reference"))
 }
 
+// fileIDCache returns the cache used by SendPhotoCached.
+func (bot *BotAPI) fileIDCache() *lruCache[string, string] {
+	bot.fileIDsOnce.Do(func() {
+		bot.fileIDs = newLRUCache[string, string](fileIDCacheSize)
+	})
+
+	return bot.fileIDs
+}
+
+// readUploadData reads the whole content of a file to upload.
+func readUploadData(file RequestFileData) (string, []byte, error) {
+	name, reader, err := file.UploadData()
+	if err != nil {
+		return "", nil, err
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	content, err := io.ReadAll(reader)
+
+	return name, content, err
+}
+
+// SendFileByID sends a file that is already stored on Telegram's servers, so
+// nothing needs to be uploaded.
+//
+// fileType is the kind of file, one of "photo", "document", "audio", "video",
+// "voice", "animation" or "sticker". Stickers can't have a caption.
+func (bot *BotAPI) SendFileByID(chatID int64, fileType, fileID, caption string) (Message, error) {
+	file := FileID(fileID)
+
+	var config Chattable
+
+	switch fileType {
+	case "photo":
+		photo := NewPhoto(chatID, file)
+		photo.Caption = caption
+		config = photo
+	case "document":
+		document := NewDocument(chatID, file)
+		document.Caption = caption
+		config = document
+	case "audio":
+		audio := NewAudio(chatID, file)
+		audio.Caption = caption
+		config = audio
+	case "video":
+		video := NewVideo(chatID, file)
+		video.Caption = caption
+		config = video
+	case "voice":
+		voice := NewVoice(chatID, file)
+		voice.Caption = caption
+		config = voice
+	case "animation":
+		animation := NewAnimation(chatID, file)
+		animation.Caption = caption
+		config = animation
+	case "sticker":
+		if caption != "" {
+			return Message{}, errors.New("stickers can't have a caption")
+		}
+		config = NewSticker(chatID, file)
+	default:
+		return Message{}, fmt.Errorf("unsupported file type %q", fileType)
+	}
+
+	return bot.Send(config)
+}
+
+// RequestBool sends a Chattable to Telegram and returns the boolean result.
+//
+// It is meant for the many methods that simply return True on success.
+func (bot *BotAPI) RequestBool(c Chattable) (bool, error) {
+	return RequestAndParse[bool](bot, c)
+}
+
+// EditInlineLiveLocation moves the live location in an inline message.
+//
+// Editing a live location sent via the bot in inline mode returns true
+// rather than the edited message, so use this instead of Send.
+func (bot *BotAPI) EditInlineLiveLocation(inlineMessageID string, latitude, longitude float64) error {
+	return bot.requestTrue(EditMessageLiveLocationConfig{
+		BaseEdit:  BaseEdit{InlineMessageID: inlineMessageID},
+		Latitude:  latitude,
+		Longitude: longitude,
+	})
+}
+
+// StopInlineLiveLocation stops updating the live location in an inline
+// message. Like EditInlineLiveLocation, it returns true rather than the
+// message.
+func (bot *BotAPI) StopInlineLiveLocation(inlineMessageID string) error {
+	return bot.requestTrue(StopMessageLiveLocationConfig{
+		BaseEdit: BaseEdit{InlineMessageID: inlineMessageID},
+	})
+}
+
+// CreateForumTopic creates a topic in a forum supergroup and returns it.
+func (bot *BotAPI) CreateForumTopic(config CreateForumTopicConfig) (ForumTopic, error) {
+	return RequestAndParse[ForumTopic](bot, config)
+}
+
+// EditGeneralForumTopic renames the General topic in a forum supergroup.
+func (bot *BotAPI) EditGeneralForumTopic(chatID int64, name string) error {
+	return bot.requestTrue(EditGeneralForumTopicConfig{ChatConfig: ChatConfig{ChatID: chatID}, Name: name})
+}
+
+// CloseGeneralForumTopic closes the General topic in a forum supergroup.
+func (bot *BotAPI) CloseGeneralForumTopic(chatID int64) error {
+	return bot.requestTrue(CloseGeneralForumTopicConfig{ChatConfig{ChatID: chatID}})
+}
+
+// ReopenGeneralForumTopic reopens the General topic in a forum supergroup.
+func (bot *BotAPI) ReopenGeneralForumTopic(chatID int64) error {
+	return bot.requestTrue(ReopenGeneralForumTopicConfig{ChatConfig{ChatID: chatID}})
+}
+
+// HideGeneralForumTopic hides the General topic in a forum supergroup.
+func (bot *BotAPI) HideGeneralForumTopic(chatID int64) error {
+	return bot.requestTrue(HideGeneralForumTopicConfig{ChatConfig{ChatID: chatID}})
+}
+
+// UnhideGeneralForumTopic unhides the General topic in a forum supergroup.
+func (bot *BotAPI) UnhideGeneralForumTopic(chatID int64) error {
+	return bot.requestTrue(UnhideGeneralForumTopicConfig{ChatConfig{ChatID: chatID}})
+}
+
+// requestTrue makes a request that returns true on success.
+func (bot *BotAPI) requestTrue(c Chattable) error {
+	ok, err := bot.RequestBool(c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s returned false", c.method())
+	}
+
+	return nil
+}
+
+// GetUpdates fetches updates.
+//
+// Offset, Limit, Timeout, and AllowedUpdates are optional.
+// To avoid stale items, set Offset to one higher than the previous item.
+// Set Timeout to a large number to reduce requests, so you can get updates
+// instantly instead of having to wait between requests.
 func (bot *BotAPI) GetUpdates(config UpdateConfig) ([]Update, error) {
-	resp, err := bot.Request(config)
+	return bot.GetUpdatesWithContext(context.Background(), config)
+}
+
+// GetUpdatesWithContext is the same as GetUpdates, but the long poll is
+// cancelled when ctx is done.
+func (bot *BotAPI) GetUpdatesWithContext(ctx context.Context, config UpdateConfig) ([]Update, error) {
+	resp, err := bot.RequestWithContext(ctx, config)
 	if err != nil {
 		return []Update{}, err
 	}
@@ -338,6 +987,125 @@ func (bot *BotAPI) GetUpdates(config UpdateConfig) ([]Update, error) {
 	return updates, err
 }
 
+// GetUpdatesWithOffset is the same as GetUpdates, but also returns the offset
+// to use for the next call: one higher than the highest update ID received,
+// or config.Offset if there were no updates.
+func (bot *BotAPI) GetUpdatesWithOffset(config UpdateConfig) ([]Update, int, error) {
+	updates, err := bot.GetUpdates(config)
+	if err != nil {
+		return nil, config.Offset, err
+	}
+
+	offset := config.Offset
+	for _, update := range updates {
+		if update.UpdateID >= offset {
+			offset = update.UpdateID + 1
+		}
+	}
+
+	return updates, offset, nil
+}
+
+// GetFile returns a File which can download a file from Telegram.
+//
+// Requires FileID.
+func (bot *BotAPI) GetFile(config FileConfig) (File, error) {
+	return RequestAndParse[File](bot, config)
+}
+
+// GetFileDirectURL returns the direct URL to download a file, which contains
+// the bot token and must not be shared.
+func (bot *BotAPI) GetFileDirectURL(fileID string) (string, error) {
+	file, err := bot.GetFile(FileConfig{FileID: fileID})
+	if err != nil {
+		return "", err
+	}
+
+	return bot.fileURL(file), nil
+}
+
+// DownloadFile writes the content of file, as returned by GetFile, to w.
+func (bot *BotAPI) DownloadFile(file File, w io.Writer) error {
+	return bot.DownloadFileWithContext(context.Background(), file, w)
+}
+
+// DownloadFileWithContext is the same as DownloadFile, but the download is
+// cancelled when ctx is done.
+func (bot *BotAPI) DownloadFileWithContext(ctx context.Context, file File, w io.Writer) error {
+	body, err := bot.openFile(ctx, file)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = io.Copy(w, body)
+
+	return err
+}
+
+// ServeFile streams the file with the given ID to w without buffering it.
+//
+// Content-Length is set from the file size and Content-Type from the file
+// extension when they are known. Nothing is written to w if the file can't
+// be fetched, so the caller can still respond with an error.
+func (bot *BotAPI) ServeFile(w http.ResponseWriter, fileID string) error {
+	file, err := bot.GetFile(FileConfig{FileID: fileID})
+	if err != nil {
+		return err
+	}
+
+	body, err := bot.openFile(context.Background(), file)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if file.FileSize > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(file.FileSize, 10))
+	}
+	if contentType := mime.TypeByExtension(path.Ext(file.FilePath)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	_, err = io.Copy(w, body)
+
+	return err
+}
+
+// openFile starts downloading file and returns the response body.
+func (bot *BotAPI) openFile(ctx context.Context, file File) (io.ReadCloser, error) {
+	if file.FilePath == "" {
+		return nil, errors.New("file has no path, get it with GetFile first")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", bot.fileURL(file), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := bot.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("downloading file %s: unexpected HTTP status %s", file.FileID, resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+// fileURL returns the URL to download file from.
+func (bot *BotAPI) fileURL(file File) string {
+	endpoint := bot.fileEndpoint
+	if endpoint == "" {
+		endpoint = FileEndpoint
+	}
+
+	return fmt.Sprintf(endpoint, bot.Token, file.FilePath)
+}
+
 // GetWebhookInfo allows you to fetch information about a webhook and if
 // one currently is set, along with pending update count and error messages.
 func (bot *BotAPI) GetWebhookInfo() (WebhookInfo, error) {
@@ -353,52 +1121,246 @@ func (bot *BotAPI) GetWebhookInfo() (WebhookInfo, error) {
 }
 
 // GetUpdatesChan starts and returns a channel for getting updates.
+//
+// The channel is closed once StopReceivingUpdates has been called and the
+// receiving goroutine has exited, so ranging over it terminates.
 func (bot *BotAPI) GetUpdatesChan(config UpdateConfig) UpdatesChannel {
 	ch := make(chan Update, bot.Buffer)
 
+	bot.logAllowedUpdates(config.AllowedUpdates)
+	go bot.receiveUpdates(config, ch, nil)
+
+	return ch
+}
+
+// updateErrorsBuffer is how many errors from fetching updates are kept for
+// GetUpdatesChanWithErrors before further ones are dropped.
+const updateErrorsBuffer = 16
+
+// GetUpdatesChanWithErrors is the same as GetUpdatesChan, but also returns a
+// channel that receives errors from fetching updates, for example to detect a
+// revoked token. Errors are still logged and retried.
+//
+// Reading the error channel is optional: errors that arrive while it is full
+// are dropped rather than holding up updates. Both channels are closed once
+// StopReceivingUpdates has been called.
+func (bot *BotAPI) GetUpdatesChanWithErrors(config UpdateConfig) (UpdatesChannel, <-chan error) {
+	ch := make(chan Update, bot.Buffer)
+	errs := make(chan error, updateErrorsBuffer)
+
+	bot.logAllowedUpdates(config.AllowedUpdates)
+	go bot.receiveUpdates(config, ch, errs)
+
+	return ch, errs
+}
+
+// receiveUpdates fetches updates into ch until StopReceivingUpdates is
+// called, sending errors to errs if it isn't nil.
+func (bot *BotAPI) receiveUpdates(config UpdateConfig, ch chan<- Update, errs chan<- error) {
+	defer close(ch)
+	if errs != nil {
+		defer close(errs)
+	}
+
+	// Cancel an in-flight long poll as soon as StopReceivingUpdates is
+	// called, rather than waiting for it to time out.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	go func() {
-		for {
+		select {
+		case <-bot.shutdownChannel:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		select {
+		case <-bot.shutdownChannel:
+			return
+		default:
+		}
+
+		updates, err := bot.GetUpdatesWithContext(ctx, config)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			interval := bot.updatesRetryInterval()
+			bot.logger().Println(err)
+			bot.logger().Printf("Failed to get updates, retrying in %s...\n", interval)
+
+			if errs != nil {
+				// The error is logged above, so drop it if nobody is
+				// reading errs.
+				select {
+				case errs <- err:
+				default:
+				}
+			}
+
 			select {
 			case <-bot.shutdownChannel:
-				close(ch)
 				return
-			default:
+			case <-time.After(interval):
+			}
+
+			continue
+		}
+
+		for _, update := range updates {
+			if update.UpdateID >= config.Offset {
+				config.Offset = update.UpdateID + 1
+
+				select {
+				case ch <- update:
+				case <-bot.shutdownChannel:
+					return
+				}
+			}
+		}
+	}
+}
+
+// defaultUpdatesRetryInterval is how long to wait after failing to get
+// updates when UpdatesRetryInterval isn't set.
+const defaultUpdatesRetryInterval = 3 * time.Second
+
+// updatesRetryInterval returns how long to wait after failing to get
+// updates.
+func (bot *BotAPI) updatesRetryInterval() time.Duration {
+	if bot.UpdatesRetryInterval > 0 {
+		return bot.UpdatesRetryInterval
+	}
+
+	return defaultUpdatesRetryInterval
+}
+
+// Poll fetches updates and passes each one to handler, without a channel to
+// consume. It is the same as PollWithConfig with a 60 second long poll.
+func (bot *BotAPI) Poll(ctx context.Context, handler func(Update) error) error {
+	return bot.PollWithConfig(ctx, UpdateConfig{Timeout: 60}, handler)
+}
+
+// PollWithConfig fetches updates using config and passes each one to handler.
+//
+// Updates are handled one at a time, in the order they were received. An
+// error returned by handler is logged along with the update ID and polling
+// carries on with the next update. The same goes for a panic in handler,
+// which is also passed to OnPanic.
+//
+// It blocks until ctx is cancelled, including during an in-flight long poll,
+// and then returns ctx.Err().
+func (bot *BotAPI) PollWithConfig(ctx context.Context, config UpdateConfig, handler func(Update) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		updates, err := bot.GetUpdatesWithContext(ctx, config)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
 			}
 
-			updates, err := bot.GetUpdates(config)
-			if err != nil {
-				log.Println(err)
-				log.Println("Failed to get updates, retrying in 3 seconds...")
-				time.Sleep(time.Second * 3)
+			interval := bot.updatesRetryInterval()
+			bot.logger().Println(err)
+			bot.logger().Printf("Failed to get updates, retrying in %s...\n", interval)
 
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(interval):
+			}
+
+			continue
+		}
+
+		for _, update := range updates {
+			if update.UpdateID < config.Offset {
 				continue
 			}
+			config.Offset = update.UpdateID + 1
 
-			for _, update := range updates {
-				if update.UpdateID >= config.Offset {
-					config.Offset = update.UpdateID + 1
-					ch <- update
-				}
+			bot.handleUpdate(handler, update)
+		}
+	}
+}
+
+// handleUpdate passes update to handler, logging a returned error. A panic in
+// handler is recovered, so a single bad update can't stop polling.
+func (bot *BotAPI) handleUpdate(handler func(Update) error, update Update) {
+	defer func() {
+		if r := recover(); r != nil {
+			bot.logger().Printf("Recovered from panic while handling update %d: %v\n", update.UpdateID, r)
+
+			if bot.OnPanic != nil {
+				bot.OnPanic(update, r)
 			}
 		}
 	}()
 
-	return ch
+	if err := handler(update); err != nil {
+		bot.logger().Printf("Failed to handle update %d: %v\n", update.UpdateID, err)
+	}
 }
 
-// StopReceivingUpdates stops the go routine which receives updates
-func (bot *BotAPI) StopReceivingUpdates() {
-	if bot.Debug {
-		log.Println("Stopping the update receiver routine...")
+// logAllowedUpdates logs which update types are received when they are
+// restricted by allowed, so that a missing type is easy to notice.
+func (bot *BotAPI) logAllowedUpdates(allowed []string) {
+	if len(allowed) == 0 {
+		return
+	}
+
+	receiving := make(map[string]bool, len(allowed))
+	for _, updateType := range allowed {
+		receiving[updateType] = true
+	}
+
+	var excluded []string
+	for _, updateType := range updateTypes {
+		if !receiving[updateType] {
+			excluded = append(excluded, updateType)
+		}
 	}
-	close(bot.shutdownChannel)
+
+	bot.logger().Printf("Receiving only %s updates, not receiving %s updates\n", strings.Join(allowed, ", "), strings.Join(excluded, ", "))
 }
 
-// ListenForWebhook registers a http handler for a webhook.
+// StopReceivingUpdates stops the go routine which receives updates. A long
+// poll in progress is cancelled rather than waited for.
+//
+// It is safe to call more than once, later calls do nothing.
+func (bot *BotAPI) StopReceivingUpdates() {
+	bot.stopOnce.Do(func() {
+		if bot.Debug {
+			bot.logger().Println("Stopping the update receiver routine...")
+		}
+		close(bot.shutdownChannel)
+	})
+}
+
+// ListenForWebhook registers a http handler for a webhook on
+// http.DefaultServeMux.
 func (bot *BotAPI) ListenForWebhook(pattern string) UpdatesChannel {
+	return bot.ListenForWebhookOnMux(http.DefaultServeMux, pattern)
+}
+
+// ListenForWebhookOnMux registers a http handler for a webhook on mux.
+func (bot *BotAPI) ListenForWebhookOnMux(mux *http.ServeMux, pattern string) UpdatesChannel {
+	handler, ch := bot.WebhookHandler()
+	mux.HandleFunc(pattern, handler)
+
+	return ch
+}
+
+// WebhookHandler returns a http handler for a webhook, and the channel it
+// sends the received updates to. The handler can be mounted on any router,
+// so several bots can run in one process.
+func (bot *BotAPI) WebhookHandler() (http.HandlerFunc, UpdatesChannel) {
 	ch := make(chan Update, bot.Buffer)
 
-	http.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
 		update, err := bot.HandleUpdate(r)
 		if err != nil {
 			errMsg, _ := json.Marshal(map[string]string{"error": err.Error()})
@@ -409,9 +1371,9 @@ func (bot *BotAPI) ListenForWebhook(pattern string) UpdatesChannel {
 		}
 
 		ch <- *update
-	})
+	}
 
-	return ch
+	return handler, ch
 }
 
 // ListenForWebhookRespReqFormat registers a http handler for a single incoming webhook.
@@ -436,8 +1398,19 @@ func (bot *BotAPI) ListenForWebhookRespReqFormat(w http.ResponseWriter, r *http.
 	return ch
 }
 
-// HandleUpdate parses and returns update received via webhook
+// HandleUpdate parses and returns update received via webhook.
+//
+// If SecretToken is set, a request without it is rejected.
 func (bot *BotAPI) HandleUpdate(r *http.Request) (*Update, error) {
+	if bot.SecretToken != "" && !validSecretToken(r.Header.Get(secretTokenHeader), []string{bot.SecretToken}) {
+		return nil, errors.New("invalid secret token")
+	}
+
+	return decodeUpdate(r)
+}
+
+// decodeUpdate parses the update in a webhook request.
+func decodeUpdate(r *http.Request) (*Update, error) {
 	if r.Method != http.MethodPost {
 		err := errors.New("wrong HTTP method required POST")
 		return nil, err
@@ -452,6 +1425,39 @@ func (bot *BotAPI) HandleUpdate(r *http.Request) (*Update, error) {
 	return &update, nil
 }
 
+// secretTokenHeader is the header in which Telegram sends the secret token
+// set with the webhook.
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// HandleUpdateWithSecret is the same as HandleUpdate, but checks that the
+// request carries one of secrets as its secret token instead of SecretToken.
+//
+// Passing both the current and the previous secret allows rotating the secret
+// token with setWebhook without rejecting updates that are already on their
+// way. Every secret is compared in constant time.
+func (bot *BotAPI) HandleUpdateWithSecret(r *http.Request, secrets ...string) (*Update, error) {
+	if !validSecretToken(r.Header.Get(secretTokenHeader), secrets) {
+		return nil, errors.New("invalid secret token")
+	}
+
+	return decodeUpdate(r)
+}
+
+// validSecretToken returns if token matches one of secrets. All secrets are
+// compared, so the time taken doesn't depend on which one matched.
+func validSecretToken(token string, secrets []string) bool {
+	valid := 0
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+
+		valid |= subtle.ConstantTimeCompare([]byte(token), []byte(secret))
+	}
+
+	return valid == 1
+}
+
 // WriteToHTTPResponse writes the request to the HTTP ResponseWriter.
 //
 // It doesn't support uploading files.
@@ -482,13 +1488,230 @@ func (bot *BotAPI) GetMyCommands() ([]BotCommand, error) {
 
 // GetMyCommandsWithConfig gets the currently registered commands with a config.
 func (bot *BotAPI) GetMyCommandsWithConfig(config GetMyCommandsConfig) ([]BotCommand, error) {
-	resp, err := bot.Request(config)
+	return RequestAndParse[[]BotCommand](bot, config)
+}
+
+// SavePreparedInlineMessage stores a message that a user of a Mini App can
+// send, and returns it with the ID to pass to the Mini App.
+func (bot *BotAPI) SavePreparedInlineMessage(config SavePreparedInlineMessageConfig) (PreparedInlineMessage, error) {
+	return RequestAndParse[PreparedInlineMessage](bot, config)
+}
+
+// GetChat gets up to date information about the chat.
+func (bot *BotAPI) GetChat(config GetChatConfig) (Chat, error) {
+	return RequestAndParse[Chat](bot, config)
+}
+
+// GetGameHighScores gets the high scores for a game.
+func (bot *BotAPI) GetGameHighScores(config GetGameHighScoresConfig) ([]GameHighScore, error) {
+	return RequestAndParse[[]GameHighScore](bot, config)
+}
+
+// GetChatAdministrators gets the administrators of a chat, other than bots,
+// in the order Telegram returns them.
+//
+// The list changes rarely, so cache it rather than calling this for every
+// message that needs an admin check.
+func (bot *BotAPI) GetChatAdministrators(config ChatAdministratorsConfig) ([]ChatMember, error) {
+	return RequestAndParse[[]ChatMember](bot, config)
+}
+
+// GetChatMember gets a specific chat member.
+func (bot *BotAPI) GetChatMember(config GetChatMemberConfig) (ChatMember, error) {
+	return RequestAndParse[ChatMember](bot, config)
+}
+
+// GetInviteLink generates a new primary invite link for a chat, revoking the
+// previous one, and returns it.
+func (bot *BotAPI) GetInviteLink(config ChatInviteLinkConfig) (string, error) {
+	return RequestAndParse[string](bot, config)
+}
+
+// CreateChatInviteLink creates an additional invite link for a chat.
+func (bot *BotAPI) CreateChatInviteLink(config CreateChatInviteLinkConfig) (ChatInviteLink, error) {
+	return RequestAndParse[ChatInviteLink](bot, config)
+}
+
+// EditChatInviteLink edits an invite link created by the bot and returns the
+// edited link.
+func (bot *BotAPI) EditChatInviteLink(config EditChatInviteLinkConfig) (ChatInviteLink, error) {
+	return RequestAndParse[ChatInviteLink](bot, config)
+}
+
+// RevokeChatInviteLink revokes an invite link created by the bot and returns
+// the revoked link.
+func (bot *BotAPI) RevokeChatInviteLink(config RevokeChatInviteLinkConfig) (ChatInviteLink, error) {
+	return RequestAndParse[ChatInviteLink](bot, config)
+}
+
+// LogOut logs the bot out from the cloud Bot API server.
+//
+// Moving a bot between servers is done in two steps. When leaving the cloud,
+// call LogOut on a bot pointed at api.telegram.org before starting it on the
+// local server. When leaving a local server, call Close on a bot pointed at
+// that local server before starting it elsewhere.
+func (bot *BotAPI) LogOut() error {
+	return bot.requestTrue(LogOutConfig{})
+}
+
+// Close closes the bot instance on a local Bot API server so it can be moved
+// to another server. See LogOut for the migration flow.
+func (bot *BotAPI) Close() error {
+	return bot.requestTrue(CloseConfig{})
+}
+
+// EditOrIgnore edits a message's text, treating an edit that doesn't change
+// anything as a success. An empty Message is returned in that case.
+func (bot *BotAPI) EditOrIgnore(config EditMessageTextConfig) (Message, error) {
+	message, err := bot.Send(config)
+	if isMessageNotModified(err) {
+		return Message{}, nil
+	}
+
+	return message, err
+}
+
+// EditOrSend is the same as EditOrIgnore, except that if the message to edit
+// no longer exists, the text is sent to the chat as a new message instead.
+//
+// Inline messages can't be replaced, so their errors are always returned.
+func (bot *BotAPI) EditOrSend(config EditMessageTextConfig) (Message, error) {
+	message, err := bot.EditOrIgnore(config)
+	if !isMessageNotFound(err) || config.InlineMessageID != "" {
+		return message, err
+	}
+
+	msg := NewMessage(config.ChatID, config.Text)
+	msg.ChannelUsername = config.ChannelUsername
+	msg.ParseMode = config.ParseMode
+	msg.Entities = config.Entities
+	msg.DisableWebPagePreview = config.DisableWebPagePreview
+	msg.LinkPreviewOptions = config.LinkPreviewOptions
+	if config.ReplyMarkup != nil {
+		msg.ReplyMarkup = config.ReplyMarkup
+	}
+
+	return bot.Send(msg)
+}
+
+// UpsertMessage keeps a single live message per chat up to date.
+//
+// If UpsertMessage has already sent a message to chatID, that message is
+// edited to contain text, otherwise a new message is sent and remembered.
+// Messages are remembered for the most recently used chats only, so a chat
+// that hasn't been updated in a while gets a new message.
+// Edits that don't change anything are not treated as errors, and if the
+// remembered message was deleted a new one is sent in its place.
+func (bot *BotAPI) UpsertMessage(chatID int64, text string) (Message, error) {
+	if messageID, ok := bot.lastMessageID(chatID); ok {
+		message, err := bot.Send(NewEditMessageText(chatID, messageID, text))
+		switch {
+		case err == nil:
+			return message, nil
+		case isMessageNotModified(err):
+			return Message{MessageID: messageID, Chat: &Chat{ID: chatID}, Text: text}, nil
+		case !isMessageNotFound(err):
+			return Message{}, err
+		}
+
+		bot.forgetLastMessageID(chatID)
+	}
+
+	message, err := bot.Send(NewMessage(chatID, text))
 	if err != nil {
-		return nil, err
+		return Message{}, err
+	}
+
+	bot.setLastMessageID(chatID, message.MessageID)
+
+	return message, nil
+}
+
+func (bot *BotAPI) lastMessageID(chatID int64) (int, bool) {
+	return bot.lastMessageCache().get(chatID)
+}
+
+func (bot *BotAPI) setLastMessageID(chatID int64, messageID int) {
+	bot.lastMessageCache().add(chatID, messageID)
+}
+
+func (bot *BotAPI) forgetLastMessageID(chatID int64) {
+	bot.lastMessageCache().remove(chatID)
+}
+
+// lastMessageCache returns the cache of messages sent by UpsertMessage.
+func (bot *BotAPI) lastMessageCache() *lruCache[int64, int] {
+	bot.lastMessagesOnce.Do(func() {
+		bot.lastMessages = newLRUCache[int64, int](lastMessagesSize)
+	})
+
+	return bot.lastMessages
+}
+
+func isMessageNotModified(err error) bool {
+	apiErr, ok := AsAPIError(err)
+
+	return ok && apiErr.IsMessageNotModified()
+}
+
+func isMessageNotFound(err error) bool {
+	apiErr, ok := AsAPIError(err)
+
+	return ok && apiErr.IsMessageNotFound()
+}
+
+// SendChatAction tells the user that something is happening on the bot's
+// side, for example that a photo is being uploaded.
+//
+// Use Typing to show the typing indicator while preparing a response, it
+// avoids sending the action again while it is still shown.
+func (bot *BotAPI) SendChatAction(config ChatActionConfig) error {
+	_, err := bot.Request(config)
+
+	return err
+}
+
+// Typing shows that the bot is typing in a chat.
+//
+// It may be called before every message sent while preparing a long response:
+// the action is only sent again once the previous one is about to expire,
+// so rapid calls for the same chat result in a single request.
+func (bot *BotAPI) Typing(chatID int64) error {
+	return bot.sendChatActionOnce(chatID, ChatTyping)
+}
+
+// sendChatActionOnce sends action to chatID unless it was sent within the
+// last chatActionInterval.
+func (bot *BotAPI) sendChatActionOnce(chatID int64, action string) error {
+	key := chatAction{chatID: chatID, action: action}
+	now := time.Now()
+
+	bot.chatActionsMu.Lock()
+	if sent, ok := bot.chatActions[key]; ok && now.Sub(sent) < chatActionInterval {
+		bot.chatActionsMu.Unlock()
+		return nil
 	}
+	if bot.chatActions == nil {
+		bot.chatActions = make(map[chatAction]time.Time)
+	}
+	// Forget actions that are no longer shown, so the map only holds chats
+	// with a recent action.
+	for other, sent := range bot.chatActions {
+		if now.Sub(sent) >= chatActionInterval {
+			delete(bot.chatActions, other)
+		}
+	}
+	bot.chatActions[key] = now
+	bot.chatActionsMu.Unlock()
 
-	var commands []BotCommand
-	err = json.Unmarshal(resp.Result, &commands)
+	err := bot.SendChatAction(NewChatAction(chatID, action))
+	if err != nil {
+		bot.chatActionsMu.Lock()
+		if bot.chatActions[key] == now {
+			delete(bot.chatActions, key)
+		}
+		bot.chatActionsMu.Unlock()
+	}
 
-	return commands, err
+	return err
 }
@@ -1,11 +1,21 @@
 package tgapimanager
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
 	"strings"
+	"sync"
+	"testing"
+	"time"
 )
 
 // BotAPI allows you to interact with the Telegram Bot API.
@@ -21,11 +31,11 @@ type TestBotAPI struct {
 	apiEndpoint string
 }
 
-// NewBotAPIWithClient creates a new BotAPI instance
+// testNewBotAPIWithClient creates a new BotAPI instance
 // and allows you to pass a http.Client.
 //
 // It requires a token, provided by @BotFather on Telegram and API endpoint.
-func TestNewBotAPIWithClient(token, apiEndpoint string, client HTTPClient) (*BotAPI, error) {
+func testNewBotAPIWithClient(token, apiEndpoint string, client HTTPClient) (*BotAPI, error) {
 	bot := &BotAPI{
 		Token:           token,
 		Client:          client,
@@ -99,7 +109,7 @@ func (bot *BotAPI) TestingMakeRequest(endpoint string, params interface{}) (*Res
 	case "getMe":
 		user := User{
 			ID:        123,
-			Username:  "testbot",
+			UserName:  "testbot",
 			FirstName: "Test",
 		}
 		userJSON, _ := json.Marshal(user)
@@ -124,3 +134,1722 @@ func (bot *BotAPI) TestGetMe() (User, error) {
 
 	return user, err
 }
+
+// newTestBot creates a bot backed by a stub Bot API server. The stub answers
+// getMe itself and passes every other request to handler.
+func newTestBot(t *testing.T, handler http.HandlerFunc) *BotAPI {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path.Base(r.URL.Path) == "getMe" {
+			fmt.Fprint(w, `{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"Test","username":"testbot"}}`)
+			return
+		}
+
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	bot, err := NewBotAPIWithAPIEndpoint("token", srv.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bot
+}
+
+// testLogger collects everything logged by the package.
+type testLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *testLogger) Println(v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lines = append(l.lines, strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lines = append(l.lines, strings.TrimSuffix(fmt.Sprintf(format, v...), "\n"))
+}
+
+func (l *testLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return strings.Join(l.lines, "\n")
+}
+
+// captureLog replaces the package logger for the duration of the test.
+func captureLog(t *testing.T) *testLogger {
+	t.Helper()
+
+	previous := log
+	logger := &testLogger{}
+	log = logger
+	t.Cleanup(func() { log = previous })
+
+	return logger
+}
+
+func TestUpsertMessageSendsAgainWhenDeleted(t *testing.T) {
+	var methods []string
+	edits := 0
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		method := path.Base(r.URL.Path)
+		methods = append(methods, method)
+
+		switch method {
+		case "sendMessage":
+			fmt.Fprintf(w, `{"ok":true,"result":{"message_id":%d,"date":0,"chat":{"id":5}}}`, 10+len(methods))
+		case "editMessageText":
+			edits++
+			if edits > 1 {
+				fmt.Fprint(w, `{"ok":false,"error_code":400,"description":"Bad Request: message to edit not found"}`)
+				return
+			}
+			fmt.Fprint(w, `{"ok":true,"result":{"message_id":11,"date":0,"chat":{"id":5}}}`)
+		}
+	})
+
+	for _, text := range []string{"one", "two", "three"} {
+		if _, err := bot.UpsertMessage(5, text); err != nil {
+			t.Fatalf("upsert %q: %v", text, err)
+		}
+	}
+
+	expected := "sendMessage,editMessageText,editMessageText,sendMessage"
+	if got := strings.Join(methods, ","); got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+
+	if id, _ := bot.lastMessageID(5); id != 14 {
+		t.Fatalf("expected cached message 14, got %d", id)
+	}
+}
+
+func TestUpsertMessageForgetsLeastRecentChat(t *testing.T) {
+	sends := 0
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if path.Base(r.URL.Path) == "sendMessage" {
+			sends++
+		}
+		fmt.Fprintf(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":%s}}}`, r.FormValue("chat_id"))
+	})
+
+	for chatID := int64(1); chatID <= lastMessagesSize+1; chatID++ {
+		if _, err := bot.UpsertMessage(chatID, "status"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := bot.UpsertMessage(1, "status"); err != nil {
+		t.Fatal(err)
+	}
+	if sends != lastMessagesSize+2 {
+		t.Errorf("expected the oldest chat to get a new message, got %d sends", sends)
+	}
+
+	if _, err := bot.UpsertMessage(lastMessagesSize+1, "status"); err != nil {
+		t.Fatal(err)
+	}
+	if sends != lastMessagesSize+2 {
+		t.Errorf("expected a recent chat's message to be edited, got %d sends", sends)
+	}
+}
+
+func TestRequestBool(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	})
+
+	ok, err := bot.RequestBool(NewDeleteMyCommands())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("expected true result")
+	}
+}
+
+func TestRequestAndParse(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if method := path.Base(r.URL.Path); method != "stopPoll" {
+			t.Errorf("expected stopPoll, got %s", method)
+		}
+		fmt.Fprint(w, `{"ok":true,"result":{"id":"poll","question":"Lunch?","options":[],"total_voter_count":3,"is_closed":true}}`)
+	})
+
+	poll, err := RequestAndParse[Poll](bot, StopPollConfig{BaseEdit{ChatID: 1, MessageID: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if poll.ID != "poll" || poll.TotalVoterCount != 3 || !poll.IsClosed {
+		t.Errorf("unexpected poll %+v", poll)
+	}
+
+	if _, err := RequestAndParse[Chat](bot, StopPollConfig{}); err == nil {
+		t.Error("expected an error for a poll without a chat")
+	}
+}
+
+func TestPollLogsHandlerErrors(t *testing.T) {
+	logger := captureLog(t)
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("offset") == "" {
+			fmt.Fprint(w, `{"ok":true,"result":[{"update_id":1},{"update_id":2}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":[]}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var handled []int
+	err := bot.Poll(ctx, func(update Update) error {
+		handled = append(handled, update.UpdateID)
+		if update.UpdateID == 1 {
+			return errors.New("boom")
+		}
+		cancel()
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(handled) != 2 {
+		t.Fatalf("expected both updates to be handled, got %v", handled)
+	}
+	if !strings.Contains(logger.String(), "Failed to handle update 1: boom") {
+		t.Fatalf("expected handler error to be logged, got %q", logger.String())
+	}
+}
+
+func TestLogOutAndClose(t *testing.T) {
+	var methods []string
+	result := "true"
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, path.Base(r.URL.Path))
+		fmt.Fprintf(w, `{"ok":true,"result":%s}`, result)
+	})
+
+	if err := bot.LogOut(); err != nil {
+		t.Fatal(err)
+	}
+	if err := bot.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(methods, ","); got != "logOut,close" {
+		t.Fatalf("unexpected methods %s", got)
+	}
+
+	result = "false"
+	if err := bot.LogOut(); err == nil {
+		t.Error("expected a false result from logOut to fail")
+	}
+	if err := bot.Close(); err == nil {
+		t.Error("expected a false result from close to fail")
+	}
+}
+
+func TestGetUpdatesCallbackQuery(t *testing.T) {
+	button := NewInlineKeyboardButtonData("Yes", "answer:yes")
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.FormValue("allowed_updates"); got != `["message","callback_query"]` {
+			t.Errorf("unexpected allowed_updates %s", got)
+		}
+
+		fmt.Fprintf(w, `{"ok":true,"result":[{"update_id":1,"callback_query":{"id":"q1","from":{"id":2,"first_name":"Ann"},"chat_instance":"c","data":%q}}]}`, *button.CallbackData)
+	})
+
+	config := NewUpdate(0)
+	config.AllowedUpdates = []string{UpdateTypeMessage, UpdateTypeCallbackQuery}
+
+	updates, err := bot.GetUpdates(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(updates) != 1 || updates[0].CallbackQuery == nil {
+		t.Fatalf("expected a callback query update, got %+v", updates)
+	}
+	if updates[0].CallbackQuery.Data != "answer:yes" {
+		t.Fatalf("unexpected callback data %q", updates[0].CallbackQuery.Data)
+	}
+}
+
+func TestGetUpdatesChatMember(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.FormValue("allowed_updates"); got != `["my_chat_member","chat_member"]` {
+			t.Errorf("unexpected allowed_updates %s", got)
+		}
+
+		fmt.Fprint(w, `{"ok":true,"result":[
+			{"update_id":1,"my_chat_member":{"chat":{"id":-100,"type":"supergroup"},"from":{"id":2},"date":0,
+				"old_chat_member":{"user":{"id":1,"is_bot":true},"status":"left"},
+				"new_chat_member":{"user":{"id":1,"is_bot":true},"status":"administrator"}}},
+			{"update_id":2,"chat_member":{"chat":{"id":-100,"type":"supergroup"},"from":{"id":3},"date":0,
+				"old_chat_member":{"user":{"id":3},"status":"left"},
+				"new_chat_member":{"user":{"id":3},"status":"member"},
+				"invite_link":{"invite_link":"https://t.me/+trial","creator":{"id":2},"is_primary":false,"is_revoked":false}}}
+		]}`)
+	})
+
+	config := NewUpdate(0)
+	config.AllowedUpdates = []string{UpdateTypeMyChatMember, UpdateTypeChatMember}
+
+	updates, err := bot.GetUpdates(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 2 || updates[0].MyChatMember == nil || updates[1].ChatMember == nil {
+		t.Fatalf("expected chat member updates, got %+v", updates)
+	}
+
+	added := updates[0].MyChatMember
+	if added.OldChatMember.Status != "left" || !added.NewChatMember.IsAdministrator() {
+		t.Errorf("unexpected bot status change %+v", added)
+	}
+
+	joined := updates[1].ChatMember
+	if joined.NewChatMember.User.ID != 3 || joined.InviteLink == nil {
+		t.Errorf("unexpected member status change %+v", joined)
+	}
+	if chat := updates[1].FromChat(); chat == nil || chat.ID != -100 {
+		t.Errorf("expected chat -100, got %v", chat)
+	}
+}
+
+func TestSendLongMessageKeepsEntitiesWhole(t *testing.T) {
+	var sent []url.Values
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		sent = append(sent, r.PostForm)
+		fmt.Fprintf(w, `{"ok":true,"result":{"message_id":%d,"date":0,"chat":{"id":1}}}`, len(sent))
+	})
+
+	text := strings.Repeat("a", 5000)
+	config := NewMessage(1, text)
+	config.Entities = []MessageEntity{
+		{Type: "bold", Offset: 10, Length: 20},
+		{Type: "bold", Offset: 4090, Length: 20},
+	}
+
+	messages, err := bot.SendLongMessage(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 || len(sent) != 2 {
+		t.Fatalf("expected two messages, got %d", len(sent))
+	}
+
+	if got := len(sent[0].Get("text")); got != 4090 {
+		t.Errorf("expected first message to end before the bold span, got length %d", got)
+	}
+	if got := len(sent[1].Get("text")); got != 910 {
+		t.Errorf("expected second message to have the rest, got length %d", got)
+	}
+
+	expected := []string{
+		`[{"type":"bold","offset":10,"length":20}]`,
+		`[{"type":"bold","offset":0,"length":20}]`,
+	}
+	for i, values := range sent {
+		if got := values.Get("entities"); got != expected[i] {
+			t.Errorf("message %d: expected entities %s, got %s", i, expected[i], got)
+		}
+	}
+}
+
+func TestEditOrSendFallsBackWhenNotFound(t *testing.T) {
+	var methods []string
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		method := path.Base(r.URL.Path)
+		methods = append(methods, method)
+
+		if method == "editMessageText" {
+			fmt.Fprint(w, `{"ok":false,"error_code":400,"description":"Bad Request: message to edit not found"}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":{"message_id":2,"date":0,"chat":{"id":1},"text":"done"}}`)
+	})
+
+	message, err := bot.EditOrSend(NewEditMessageText(1, 1, "done"))
+	if err != nil {
+		t.Fatalf("expected fallback send, got error %v", err)
+	}
+	if message.MessageID != 2 {
+		t.Fatalf("expected new message 2, got %d", message.MessageID)
+	}
+	if got := strings.Join(methods, ","); got != "editMessageText,sendMessage" {
+		t.Fatalf("unexpected methods %s", got)
+	}
+}
+
+func TestSendPhotoUploadAndFileID(t *testing.T) {
+	var contentTypes, photos []string
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+
+		if err := r.ParseMultipartForm(1 << 20); err == nil {
+			file, header, err := r.FormFile("photo")
+			if err != nil {
+				t.Errorf("expected an uploaded photo: %v", err)
+				return
+			}
+			file.Close()
+			photos = append(photos, "upload:"+header.Filename)
+		} else {
+			photos = append(photos, r.FormValue("photo"))
+		}
+
+		fmt.Fprint(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1}}}`)
+	})
+
+	if _, err := bot.Send(NewPhoto(1, FileBytes{Name: "cat.jpg", Bytes: []byte("jpeg")})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bot.Send(NewPhoto(1, FileID("AgAD"))); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(contentTypes[0], "multipart/form-data") {
+		t.Errorf("expected upload to be multipart, got %s", contentTypes[0])
+	}
+	if contentTypes[1] != "application/x-www-form-urlencoded" {
+		t.Errorf("expected file ID to be sent as a param, got %s", contentTypes[1])
+	}
+	if got := strings.Join(photos, ","); got != "upload:cat.jpg,AgAD" {
+		t.Errorf("unexpected photos %s", got)
+	}
+}
+
+func TestNewBotAPIWithValidationTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	start := time.Now()
+	_, err := NewBotAPIWithOptions("token",
+		WithAPIEndpoint(srv.URL+"/bot%s/%s"),
+		WithValidationTimeout(50*time.Millisecond),
+	)
+
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("construction took %s", elapsed)
+	}
+}
+
+func TestRawRequest(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if method := path.Base(r.URL.Path); method != "sendFutureThing" {
+			t.Errorf("unexpected method %s", method)
+		}
+		if r.FormValue("chat_id") != "1" || r.FormValue("thing") != `{"shiny":true}` {
+			t.Errorf("unexpected params %v", r.PostForm)
+		}
+
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	})
+
+	ok, err := bot.RequestBool(RawRequest{
+		Method: "sendFutureThing",
+		Fields: Params{"chat_id": "1", "thing": `{"shiny":true}`},
+	})
+	if err != nil || !ok {
+		t.Fatalf("expected true, got %v, %v", ok, err)
+	}
+}
+
+func TestMakeRequestHTTPStatus(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprint(w, "<html>502 Bad Gateway</html>")
+	})
+
+	_, err := bot.Send(NewMessage(1, "hi"))
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+	if apiErr.HTTPStatus != http.StatusBadGateway {
+		t.Errorf("expected HTTP status 502, got %d", apiErr.HTTPStatus)
+	}
+	if !strings.Contains(apiErr.Error(), "502 Bad Gateway") {
+		t.Errorf("unexpected message %q", apiErr.Error())
+	}
+}
+
+func TestSendVideoUploadsThumb(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("failed to parse the upload: %v", err)
+			return
+		}
+		for _, field := range []string{"video", "thumb"} {
+			if _, ok := r.MultipartForm.File[field]; !ok {
+				t.Errorf("expected %s to be uploaded", field)
+			}
+		}
+
+		fmt.Fprint(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1}}}`)
+	})
+
+	config := NewVideo(1, FileBytes{Name: "clip.mp4", Bytes: []byte("mp4")})
+	config.Thumb = FileBytes{Name: "thumb.jpg", Bytes: []byte("jpeg")}
+
+	if _, err := bot.Send(config); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetChatPhotoUploads(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if method := path.Base(r.URL.Path); method != "setChatPhoto" {
+			t.Errorf("expected setChatPhoto, got %s", method)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("failed to parse the upload: %v", err)
+			return
+		}
+		if _, ok := r.MultipartForm.File["photo"]; !ok {
+			t.Error("expected photo to be uploaded")
+		}
+		if got := r.FormValue("chat_id"); got != "-100" {
+			t.Errorf("expected chat_id -100, got %s", got)
+		}
+
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	})
+
+	if _, err := bot.Request(NewSetChatPhoto(-100, FileBytes{Name: "logo.jpg", Bytes: []byte("jpeg")})); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bot.Request(NewSetChatPhoto(-100, FileID("photo-id"))); err == nil {
+		t.Error("expected a file ID to be rejected")
+	}
+}
+
+func TestUploadFilesErrorCode(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"ok":false,"error_code":400,"description":"Bad Request: PHOTO_INVALID_DIMENSIONS"}`)
+	})
+
+	_, err := bot.Send(NewPhoto(1, FileBytes{Name: "cat.jpg", Bytes: []byte("jpeg")}))
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an API error, got %v", err)
+	}
+	if apiErr.Code != 400 {
+		t.Errorf("expected error code 400, got %d", apiErr.Code)
+	}
+}
+
+func TestMakeRequestNonJSONBody(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprint(w, "<html>502 Bad Gateway</html>")
+	})
+
+	_, err := bot.Send(NewMessage(1, "hi"))
+
+	expected := `unexpected "text/html" response with HTTP status 502 Bad Gateway: <html>502 Bad Gateway</html>`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+}
+
+func TestMakeRequestCancelledMidBody(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"ok":true,"result":{"message_id":1,`)
+		w.(http.Flusher).Flush()
+
+		<-r.Context().Done()
+	})
+	bot.Client = cancelAfterFirstRead{client: bot.Client, cancel: cancel}
+
+	_, err := bot.MakeRequestWithContext(ctx, "sendMessage", Params{"chat_id": "1", "text": "hi"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, ok := AsAPIError(err); ok {
+		t.Errorf("expected the cancellation not to be reported as an API error, got %v", err)
+	}
+}
+
+// cancelAfterFirstRead calls cancel once the first part of a response body
+// has been read, to cancel a request in the middle of its body.
+type cancelAfterFirstRead struct {
+	client HTTPClient
+	cancel context.CancelFunc
+}
+
+func (c cancelAfterFirstRead) Do(req *http.Request) (*http.Response, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	resp.Body = &cancellingBody{ReadCloser: resp.Body, cancel: c.cancel}
+
+	return resp, nil
+}
+
+type cancellingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancellingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.cancel()
+
+	return n, err
+}
+
+func TestSendFileByID(t *testing.T) {
+	fileTypes := map[string]string{
+		"photo":     "sendPhoto",
+		"document":  "sendDocument",
+		"audio":     "sendAudio",
+		"video":     "sendVideo",
+		"voice":     "sendVoice",
+		"animation": "sendAnimation",
+		"sticker":   "sendSticker",
+	}
+
+	for fileType, method := range fileTypes {
+		t.Run(fileType, func(t *testing.T) {
+			bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+				if got := path.Base(r.URL.Path); got != method {
+					t.Errorf("expected %s, got %s", method, got)
+				}
+				if ct := r.Header.Get("Content-Type"); ct != "application/x-www-form-urlencoded" {
+					t.Errorf("expected no upload, got %s", ct)
+				}
+				if got := r.FormValue(fileType); got != "file-id" {
+					t.Errorf("expected %s=file-id, got %q", fileType, got)
+				}
+
+				fmt.Fprint(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1}}}`)
+			})
+
+			if _, err := bot.SendFileByID(1, fileType, "file-id", ""); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestSendWithoutChatFails(t *testing.T) {
+	requests := 0
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	})
+
+	configs := []Chattable{
+		MessageConfig{Text: "nowhere"},
+		NewMediaGroup(0, []interface{}{NewInputMediaPhoto(FileID("a")), NewInputMediaPhoto(FileID("b"))}),
+		GetChatConfig{},
+	}
+	for _, config := range configs {
+		_, err := bot.Request(config)
+		if err == nil || err.Error() != "chat_id or channel username required" {
+			t.Errorf("%s: expected missing chat error, got %v", config.method(), err)
+		}
+	}
+	if requests != 0 {
+		t.Fatalf("expected no requests, got %d", requests)
+	}
+}
+
+func TestSendMediaGroupAttachesUploads(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("failed to parse the upload: %v", err)
+			return
+		}
+
+		expected := `[{"type":"photo","media":"attach://file-0"},{"type":"video","media":"existing-id","thumb":"attach://file-1-thumb"}]`
+		if got := r.FormValue("media"); got != expected {
+			t.Errorf("expected media %s, got %s", expected, got)
+		}
+		for _, field := range []string{"file-0", "file-1-thumb"} {
+			if _, ok := r.MultipartForm.File[field]; !ok {
+				t.Errorf("expected %s to be uploaded", field)
+			}
+		}
+
+		fmt.Fprint(w, `{"ok":true,"result":[{"message_id":1,"date":0,"chat":{"id":1}},{"message_id":2,"date":0,"chat":{"id":1}}]}`)
+	})
+
+	video := NewInputMediaVideo(FileID("existing-id"))
+	video.Thumb = FileBytes{Name: "thumb.jpg", Bytes: []byte("jpeg")}
+
+	messages, err := bot.SendMediaGroup(NewMediaGroup(1, []interface{}{
+		NewInputMediaPhoto(FileBytes{Name: "cat.jpg", Bytes: []byte("jpeg")}),
+		video,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected two messages, got %d", len(messages))
+	}
+}
+
+func TestTypingIsDebounced(t *testing.T) {
+	requests := 0
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := bot.Typing(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bot.Typing(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if requests != 2 {
+		t.Fatalf("expected one request per chat, got %d", requests)
+	}
+}
+
+func TestTypingForgetsExpiredActions(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	})
+
+	if err := bot.Typing(1); err != nil {
+		t.Fatal(err)
+	}
+	expired := chatAction{chatID: 1, action: ChatTyping}
+	bot.chatActions[expired] = time.Now().Add(-chatActionInterval)
+
+	if err := bot.Typing(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := bot.chatActions[expired]; ok || len(bot.chatActions) != 1 {
+		t.Errorf("expected only the current action to be kept, got %v", bot.chatActions)
+	}
+}
+
+func TestStopReceivingUpdatesClosesChannel(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("offset") == "" {
+			fmt.Fprint(w, `{"ok":true,"result":[{"update_id":1},{"update_id":2},{"update_id":3}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":[]}`)
+	})
+	bot.Buffer = 1
+
+	updates := bot.GetUpdatesChan(NewUpdate(0))
+	<-updates
+	bot.StopReceivingUpdates()
+
+	done := make(chan struct{})
+	go func() {
+		for range updates {
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("updates channel was not closed")
+	}
+
+	updates.Clear()
+}
+
+func TestCopyMessage(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if method := path.Base(r.URL.Path); method != "copyMessage" {
+			t.Errorf("expected copyMessage, got %s", method)
+		}
+		if got := r.FormValue("caption"); got != "copied" {
+			t.Errorf("expected caption copied, got %q", got)
+		}
+		fmt.Fprint(w, `{"ok":true,"result":{"message_id":99}}`)
+	})
+
+	config := NewCopyMessage(1, 2, 3)
+	config.Caption = "copied"
+
+	messageID, err := bot.CopyMessage(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if messageID.MessageID != 99 {
+		t.Errorf("expected message ID 99, got %d", messageID.MessageID)
+	}
+}
+
+func TestMakeRequestRetriesServerError(t *testing.T) {
+	var calls int
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"ok":false,"error_code":500,"description":"Internal Server Error"}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1}}}`)
+	})
+
+	message, err := bot.Send(NewMessage(1, "hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.MessageID != 1 || calls != 2 {
+		t.Errorf("expected message to be sent after one retry, got message %d after %d calls", message.MessageID, calls)
+	}
+
+	calls = 0
+	bot.RetryPolicy.DisableServerErrorRetry = true
+
+	if _, err := bot.Send(NewMessage(1, "hello")); err == nil {
+		t.Error("expected server error without retry")
+	}
+	if calls != 1 {
+		t.Errorf("expected a single call, got %d", calls)
+	}
+}
+
+func TestGetChat(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if method := path.Base(r.URL.Path); method != "getChat" {
+			t.Errorf("expected getChat, got %s", method)
+		}
+		if got := r.FormValue("chat_id"); got != "@example" {
+			t.Errorf("expected chat_id @example, got %s", got)
+		}
+		fmt.Fprint(w, `{"ok":true,"result":{"id":-1001234567890,"type":"supergroup","title":"Example","permissions":{"can_send_messages":true}}}`)
+	})
+
+	chat, err := bot.GetChat(GetChatConfig{ChatConfig{SuperGroupUsername: "@example"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat.ID != -1001234567890 || !chat.IsSuperGroup() || chat.Title != "Example" {
+		t.Errorf("unexpected chat %+v", chat)
+	}
+	if chat.Permissions == nil || !chat.Permissions.CanSendMessages {
+		t.Errorf("expected chat permissions to be decoded, got %+v", chat.Permissions)
+	}
+}
+
+func TestChatInviteLinks(t *testing.T) {
+	var methods []string
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		method := path.Base(r.URL.Path)
+		methods = append(methods, method)
+
+		switch method {
+		case "exportChatInviteLink":
+			fmt.Fprint(w, `{"ok":true,"result":"https://t.me/+primary"}`)
+		case "revokeChatInviteLink":
+			if got := r.FormValue("invite_link"); got != "https://t.me/+trial" {
+				t.Errorf("expected invite_link https://t.me/+trial, got %s", got)
+			}
+			fmt.Fprint(w, `{"ok":true,"result":{"invite_link":"https://t.me/+trial","creator":{"id":1},"is_primary":false,"is_revoked":true}}`)
+		default:
+			fmt.Fprint(w, `{"ok":true,"result":{"invite_link":"https://t.me/+trial","creator":{"id":1},"creates_join_request":true,"is_primary":false,"is_revoked":false,"name":"Trial"}}`)
+		}
+	})
+
+	chat := ChatConfig{ChatID: -100}
+
+	primary, err := bot.GetInviteLink(ChatInviteLinkConfig{chat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if primary != "https://t.me/+primary" {
+		t.Errorf("unexpected primary link %s", primary)
+	}
+
+	link, err := bot.CreateChatInviteLink(CreateChatInviteLinkConfig{ChatConfig: chat, Name: "Trial", CreatesJoinRequest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Name != "Trial" || !link.CreatesJoinRequest || link.Creator.ID != 1 {
+		t.Errorf("unexpected created link %+v", link)
+	}
+
+	if _, err := bot.EditChatInviteLink(EditChatInviteLinkConfig{ChatConfig: chat, InviteLink: link.InviteLink, Name: "Trial"}); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err = bot.RevokeChatInviteLink(RevokeChatInviteLinkConfig{ChatConfig: chat, InviteLink: link.InviteLink})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !link.IsRevoked {
+		t.Errorf("expected revoked link, got %+v", link)
+	}
+
+	expected := "exportChatInviteLink,createChatInviteLink,editChatInviteLink,revokeChatInviteLink"
+	if got := strings.Join(methods, ","); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestGetChatMember(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("chat_id") != "-100" || r.FormValue("user_id") != "7" {
+			t.Errorf("unexpected params %v", r.Form)
+		}
+		fmt.Fprint(w, `{"ok":true,"result":{"user":{"id":7},"status":"administrator","can_restrict_members":true}}`)
+	})
+
+	member, err := bot.GetChatMember(GetChatMemberConfig{ChatMemberConfig{ChatID: -100, UserID: 7}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !member.IsAdministrator() || member.IsCreator() {
+		t.Errorf("expected an administrator, got status %q", member.Status)
+	}
+	if !member.CanRestrictMembers {
+		t.Error("expected CanRestrictMembers to be decoded")
+	}
+}
+
+func TestGetUpdatesChanLogsAllowedUpdates(t *testing.T) {
+	logger := captureLog(t)
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true,"result":[]}`)
+	})
+
+	config := NewUpdate(0)
+	config.AllowedUpdates = []string{UpdateTypeMessage, UpdateTypeEditedMessage}
+
+	bot.GetUpdatesChan(config)
+	bot.StopReceivingUpdates()
+
+	output := logger.String()
+	if !strings.Contains(output, "Receiving only message, edited_message updates") {
+		t.Errorf("expected received update types to be logged, got %q", output)
+	}
+	if !strings.Contains(output, "not receiving channel_post, edited_channel_post, inline_query, chosen_inline_result, callback_query,") {
+		t.Errorf("expected excluded update types to be logged, got %q", output)
+	}
+}
+
+func TestConnectionOptionsReuseConnections(t *testing.T) {
+	var mu sync.Mutex
+	var conns int
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path.Base(r.URL.Path) == "getMe" {
+			fmt.Fprint(w, `{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"Test","username":"test_bot"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	}))
+	srv.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	bot, err := NewBotAPIWithOptions("token",
+		WithAPIEndpoint(srv.URL+"/bot%s/%s"),
+		WithMaxIdleConnsPerHost(10),
+		WithIdleConnTimeout(time.Minute),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport := bot.Client.(*http.Client).Transport.(*http.Transport)
+	if transport.MaxIdleConnsPerHost != 10 || transport.IdleConnTimeout != time.Minute {
+		t.Errorf("expected transport to be tuned, got %d idle connections and %s timeout", transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+
+	for i := 0; i < 50; i++ {
+		if _, err := bot.RequestBool(NewDeleteMyCommands()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if conns != 1 {
+		t.Errorf("expected a single reused connection, got %d", conns)
+	}
+}
+
+func TestConnectionOptionsWithClientFails(t *testing.T) {
+	_, err := NewBotAPIWithOptions("token", WithClient(&http.Client{}), WithMaxIdleConnsPerHost(10))
+	if err == nil {
+		t.Fatal("expected an error combining WithClient with connection options")
+	}
+}
+
+func TestGetChatAdministrators(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if method := path.Base(r.URL.Path); method != "getChatAdministrators" {
+			t.Errorf("expected getChatAdministrators, got %s", method)
+		}
+		fmt.Fprint(w, `{"ok":true,"result":[{"user":{"id":1},"status":"creator"},{"user":{"id":2},"status":"administrator"}]}`)
+	})
+
+	members, err := bot.GetChatAdministrators(ChatAdministratorsConfig{ChatConfig{ChatID: -100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 || !members[0].IsCreator() || members[1].User.ID != 2 {
+		t.Errorf("unexpected administrators %+v", members)
+	}
+}
+
+func TestGetFileAndDownload(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/file/") {
+			if r.URL.Path != "/file/bottoken/photos/file_1.jpg" {
+				t.Errorf("unexpected download path %s", r.URL.Path)
+			}
+			fmt.Fprint(w, "image data")
+			return
+		}
+		if got := r.FormValue("file_id"); got != "file-id" {
+			t.Errorf("expected file_id file-id, got %s", got)
+		}
+		fmt.Fprint(w, `{"ok":true,"result":{"file_id":"file-id","file_unique_id":"unique","file_size":10,"file_path":"photos/file_1.jpg"}}`)
+	})
+	bot.Token = "token"
+	bot.SetFileEndpoint(strings.Replace(bot.apiEndpoint, "/bot%s/%s", "/file/bot%s/%s", 1))
+
+	file, err := bot.GetFile(FileConfig{FileID: "file-id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+	if err := bot.DownloadFile(file, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "image data" {
+		t.Errorf("expected file content, got %q", buf.String())
+	}
+
+	link, err := bot.GetFileDirectURL("file-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(link, "/file/bottoken/photos/file_1.jpg") {
+		t.Errorf("unexpected direct URL %s", link)
+	}
+}
+
+func TestServeFileStreamsWithHeaders(t *testing.T) {
+	const size = 4 << 20
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/file/") {
+			io.Copy(w, io.LimitReader(zeroReader{}, size))
+			return
+		}
+		fmt.Fprintf(w, `{"ok":true,"result":{"file_id":"file-id","file_unique_id":"unique","file_size":%d,"file_path":"videos/file_2.mp4"}}`, size)
+	})
+	bot.Token = "token"
+	bot.SetFileEndpoint(strings.Replace(bot.apiEndpoint, "/bot%s/%s", "/file/bot%s/%s", 1))
+
+	w := httptest.NewRecorder()
+	if err := bot.ServeFile(w, "file-id"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := w.Header().Get("Content-Length"); got != strconv.Itoa(size) {
+		t.Errorf("expected Content-Length %d, got %s", size, got)
+	}
+	if got := w.Header().Get("Content-Type"); got != "video/mp4" {
+		t.Errorf("expected Content-Type video/mp4, got %s", got)
+	}
+	if w.Body.Len() != size {
+		t.Errorf("expected %d bytes, got %d", size, w.Body.Len())
+	}
+}
+
+func TestServeFileWritesNothingOnError(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/file/") {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":{"file_id":"file-id","file_unique_id":"unique","file_size":10,"file_path":"photos/file_1.jpg"}}`)
+	})
+	bot.SetFileEndpoint(strings.Replace(bot.apiEndpoint, "/bot%s/%s", "/file/bot%s/%s", 1))
+
+	w := httptest.NewRecorder()
+	if err := bot.ServeFile(w, "file-id"); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+	if len(w.Header()) != 0 || w.Body.Len() != 0 {
+		t.Errorf("expected nothing written, got headers %v and %d bytes", w.Header(), w.Body.Len())
+	}
+}
+
+// zeroReader is an endless stream of zero bytes.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+
+	return len(p), nil
+}
+
+func TestPollRecoversHandlerPanics(t *testing.T) {
+	logger := captureLog(t)
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("offset") == "" {
+			fmt.Fprint(w, `{"ok":true,"result":[{"update_id":1},{"update_id":2}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":[]}`)
+	})
+
+	var panicked []int
+	bot.OnPanic = func(update Update, recovered interface{}) {
+		if recovered != "boom" {
+			t.Errorf("expected recovered value boom, got %v", recovered)
+		}
+		panicked = append(panicked, update.UpdateID)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var handled []int
+	err := bot.Poll(ctx, func(update Update) error {
+		handled = append(handled, update.UpdateID)
+		if update.UpdateID == 1 {
+			panic("boom")
+		}
+		cancel()
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(handled) != 2 {
+		t.Fatalf("expected polling to continue after the panic, got %v", handled)
+	}
+	if len(panicked) != 1 || panicked[0] != 1 {
+		t.Fatalf("expected OnPanic to be called for update 1, got %v", panicked)
+	}
+	if !strings.Contains(logger.String(), "Recovered from panic while handling update 1: boom") {
+		t.Fatalf("expected panic to be logged, got %q", logger.String())
+	}
+}
+
+func TestDefaultParseMode(t *testing.T) {
+	var parseModes []string
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		parseModes = append(parseModes, r.FormValue("parse_mode"))
+		fmt.Fprint(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1}}}`)
+	})
+	bot.DefaultParseMode = "HTML"
+
+	plain := NewMessage(1, "<b>hello</b>")
+
+	explicit := NewMessage(1, "*hello*")
+	explicit.ParseMode = "MarkdownV2"
+
+	withEntities := NewMessage(1, "hello")
+	withEntities.Entities = []MessageEntity{{Type: "bold", Offset: 0, Length: 5}}
+
+	for _, config := range []MessageConfig{plain, explicit, withEntities} {
+		if _, err := bot.Send(config); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{"HTML", "MarkdownV2", ""}
+	if strings.Join(parseModes, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected parse modes %q, got %q", expected, parseModes)
+	}
+}
+
+func TestMakeRequestRetriesFloodWait(t *testing.T) {
+	captureLog(t)
+
+	var calls int
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1","parameters":{"retry_after":1}}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	})
+	bot.RetryPolicy.RetryOnFloodWait = true
+
+	start := time.Now()
+	if _, err := bot.RequestBool(NewDeleteMyCommands()); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("expected a single retry, got %d calls", calls)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected to wait for retry_after, waited %s", elapsed)
+	}
+}
+
+func TestMakeRequestFloodWaitRespectsContext(t *testing.T) {
+	captureLog(t)
+
+	var calls int
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 30","parameters":{"retry_after":30}}`)
+	})
+	bot.RetryPolicy.RetryOnFloodWait = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := bot.RequestWithContext(ctx, NewDeleteMyCommands())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the wait to be cut short, waited %s", elapsed)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single call, got %d", calls)
+	}
+}
+
+func TestHandleUpdateWithSecret(t *testing.T) {
+	bot := &BotAPI{}
+
+	tests := []struct {
+		token string
+		valid bool
+	}{
+		{"current-secret", true},
+		{"previous-secret", true},
+		{"unknown-secret", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id":1}`))
+		if test.token != "" {
+			r.Header.Set("X-Telegram-Bot-Api-Secret-Token", test.token)
+		}
+
+		update, err := bot.HandleUpdateWithSecret(r, "current-secret", "previous-secret")
+		if test.valid {
+			if err != nil || update.UpdateID != 1 {
+				t.Errorf("token %q: expected update 1, got %v, %v", test.token, update, err)
+			}
+		} else if err == nil {
+			t.Errorf("token %q: expected the update to be rejected", test.token)
+		}
+	}
+}
+
+func TestStopReceivingUpdatesCancelsLongPoll(t *testing.T) {
+	polling := make(chan struct{}, 1)
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		// The request context is only cancelled once the body has been read.
+		r.ParseForm()
+		polling <- struct{}{}
+
+		select {
+		case <-r.Context().Done():
+		case <-time.After(30 * time.Second):
+			fmt.Fprint(w, `{"ok":true,"result":[]}`)
+		}
+	})
+
+	config := NewUpdate(0)
+	config.Timeout = 30
+	updates := bot.GetUpdatesChan(config)
+
+	<-polling
+	start := time.Now()
+	bot.StopReceivingUpdates()
+
+	select {
+	case _, ok := <-updates:
+		if ok {
+			t.Fatal("expected no updates")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("long poll was not cancelled")
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("stopping took %s", elapsed)
+	}
+}
+
+func TestStopReceivingUpdatesTwice(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true,"result":[]}`)
+	})
+
+	bot.StopReceivingUpdates()
+	bot.StopReceivingUpdates()
+}
+
+func TestGetUpdatesWithOffset(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("offset") == "5" {
+			fmt.Fprint(w, `{"ok":true,"result":[{"update_id":10},{"update_id":12}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":[]}`)
+	})
+
+	updates, offset, err := bot.GetUpdatesWithOffset(NewUpdate(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 2 || offset != 13 {
+		t.Errorf("expected 2 updates and offset 13, got %d updates and offset %d", len(updates), offset)
+	}
+
+	updates, offset, err = bot.GetUpdatesWithOffset(NewUpdate(13))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 0 || offset != 13 {
+		t.Errorf("expected no updates and offset 13, got %d updates and offset %d", len(updates), offset)
+	}
+}
+
+func TestListenForWebhookOnMux(t *testing.T) {
+	bot := &BotAPI{Buffer: 1}
+
+	mux := http.NewServeMux()
+	updates := bot.ListenForWebhookOnMux(mux, "/webhook")
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id":42}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	select {
+	case update := <-updates:
+		if update.UpdateID != 42 {
+			t.Errorf("expected update 42, got %d", update.UpdateID)
+		}
+	default:
+		t.Fatal("expected an update on the channel")
+	}
+}
+
+func TestSavePreparedInlineMessage(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		expected := `{"type":"article","id":"1","title":"Share","input_message_content":{"message_text":"Hello"}}`
+		if got := r.FormValue("result"); got != expected {
+			t.Errorf("expected result %s, got %s", expected, got)
+		}
+		if r.FormValue("allow_user_chats") != "true" || r.FormValue("allow_group_chats") != "" {
+			t.Errorf("unexpected chat types %v", r.Form)
+		}
+		fmt.Fprint(w, `{"ok":true,"result":{"id":"prepared-1","expiration_date":1700000000}}`)
+	})
+
+	message, err := bot.SavePreparedInlineMessage(SavePreparedInlineMessageConfig{
+		UserID:         7,
+		Result:         NewInlineQueryResultArticle("1", "Share", "Hello"),
+		AllowUserChats: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.ID != "prepared-1" || message.ExpirationDate != 1700000000 {
+		t.Errorf("unexpected prepared message %+v", message)
+	}
+}
+
+func TestHandleUpdateSecretToken(t *testing.T) {
+	bot := &BotAPI{SecretToken: "secret"}
+
+	request := func(token string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id":1}`))
+		if token != "" {
+			r.Header.Set("X-Telegram-Bot-Api-Secret-Token", token)
+		}
+		return r
+	}
+
+	if _, err := bot.HandleUpdate(request("secret")); err != nil {
+		t.Errorf("expected the update to be accepted, got %v", err)
+	}
+	for _, token := range []string{"", "wrong"} {
+		if _, err := bot.HandleUpdate(request(token)); err == nil {
+			t.Errorf("token %q: expected the update to be rejected", token)
+		}
+	}
+
+	params, err := WebhookConfig{SecretToken: "secret"}.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["secret_token"] != "secret" {
+		t.Errorf("expected secret_token param, got %v", params)
+	}
+}
+
+func TestSendPhotoCached(t *testing.T) {
+	var uploads, byID int
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			uploads++
+			fmt.Fprint(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1},"photo":[{"file_id":"small"},{"file_id":"large"}]}}`)
+			return
+		}
+
+		byID++
+		if got := r.FormValue("photo"); got != "large" {
+			t.Errorf("expected the cached file ID large, got %q", got)
+		}
+		fmt.Fprint(w, `{"ok":true,"result":{"message_id":2,"date":0,"chat":{"id":1},"photo":[{"file_id":"large"}]}}`)
+	})
+
+	photo := FileBytes{Name: "photo.jpg", Bytes: []byte("image data")}
+	for i := 0; i < 3; i++ {
+		if _, err := bot.SendPhotoCached(1, photo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if uploads != 1 || byID != 2 {
+		t.Errorf("expected 1 upload and 2 sends by file ID, got %d and %d", uploads, byID)
+	}
+
+	other := FileReader{Name: "other.jpg", Reader: strings.NewReader("other image data")}
+	if _, err := bot.SendPhotoCached(1, other); err != nil {
+		t.Fatal(err)
+	}
+	if uploads != 2 {
+		t.Errorf("expected different content to be uploaded, got %d uploads", uploads)
+	}
+}
+
+func TestSendPhotoCachedRejectedFileID(t *testing.T) {
+	var uploads int
+	byIDError := `{"ok":false,"error_code":400,"description":"Bad Request: wrong file identifier/HTTP URL specified"}`
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			uploads++
+			fmt.Fprint(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1},"photo":[{"file_id":"large"}]}}`)
+			return
+		}
+
+		fmt.Fprint(w, byIDError)
+	})
+
+	photo := FileBytes{Name: "photo.jpg", Bytes: []byte("image data")}
+	for i := 0; i < 2; i++ {
+		if _, err := bot.SendPhotoCached(1, photo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if uploads != 2 {
+		t.Fatalf("expected a rejected file ID to be uploaded again, got %d uploads", uploads)
+	}
+
+	byIDError = `{"ok":false,"error_code":400,"description":"Bad Request: not enough rights to send photos to the chat"}`
+	_, err := bot.SendPhotoCached(1, photo)
+	if apiErr, ok := AsAPIError(err); !ok || !strings.Contains(apiErr.Message, "not enough rights") {
+		t.Errorf("expected the API error to be returned, got %v", err)
+	}
+	if uploads != 2 {
+		t.Errorf("expected no upload for an unrelated error, got %d uploads", uploads)
+	}
+}
+
+func TestBotSetLogger(t *testing.T) {
+	packageLogger := captureLog(t)
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	})
+	bot.Debug = true
+
+	if err := bot.SetLogger(nil); err == nil {
+		t.Error("expected an error for a nil logger")
+	}
+
+	logger := &testLogger{}
+	if err := bot.SetLogger(logger); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bot.RequestBool(NewDeleteMyCommands()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(logger.String(), "Endpoint: deleteMyCommands") {
+		t.Errorf("expected debug output in the bot's logger, got %q", logger.String())
+	}
+	if packageLogger.String() != "" {
+		t.Errorf("expected nothing in the package logger, got %q", packageLogger.String())
+	}
+}
+
+func TestInlineLiveLocation(t *testing.T) {
+	var methods []string
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, path.Base(r.URL.Path))
+		if got := r.FormValue("inline_message_id"); got != "inline" {
+			t.Errorf("expected inline_message_id inline, got %q", got)
+		}
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	})
+
+	if err := bot.EditInlineLiveLocation("inline", 51.5, -0.1); err != nil {
+		t.Fatal(err)
+	}
+	if err := bot.StopInlineLiveLocation("inline"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(methods, ","); got != "editMessageLiveLocation,stopMessageLiveLocation" {
+		t.Errorf("unexpected methods %s", got)
+	}
+}
+
+func TestGetUpdatesChanWithErrors(t *testing.T) {
+	captureLog(t)
+
+	var mu sync.Mutex
+	calls := 0
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		call := calls
+		mu.Unlock()
+
+		if call == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"ok":false,"error_code":401,"description":"Unauthorized"}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":[{"update_id":1}]}`)
+	})
+	bot.UpdatesRetryInterval = 10 * time.Millisecond
+
+	updates, errs := bot.GetUpdatesChanWithErrors(NewUpdate(0))
+
+	select {
+	case err := <-errs:
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || apiErr.Code != 401 {
+			t.Errorf("expected a 401 error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an error")
+	}
+
+	select {
+	case update := <-updates:
+		if update.UpdateID != 1 {
+			t.Errorf("expected update 1, got %d", update.UpdateID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an update after retrying")
+	}
+
+	bot.StopReceivingUpdates()
+
+	select {
+	case _, ok := <-errs:
+		for ok {
+			_, ok = <-errs
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the error channel to be closed")
+	}
+}
+
+func TestGetUpdatesChanWithErrorsUnread(t *testing.T) {
+	captureLog(t)
+
+	var mu sync.Mutex
+	calls := 0
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		call := calls
+		mu.Unlock()
+
+		if call <= 2*updateErrorsBuffer {
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, `{"ok":false,"error_code":502,"description":"Bad Gateway"}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":[{"update_id":1}]}`)
+	})
+	bot.UpdatesRetryInterval = time.Millisecond
+
+	updates, _ := bot.GetUpdatesChanWithErrors(NewUpdate(0))
+	defer bot.StopReceivingUpdates()
+
+	select {
+	case update := <-updates:
+		if update.UpdateID != 1 {
+			t.Errorf("expected update 1, got %d", update.UpdateID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected updates to keep flowing while errors aren't read")
+	}
+}
+
+func TestForumTopics(t *testing.T) {
+	var method string
+	var form url.Values
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		method, form = path.Base(r.URL.Path), r.PostForm
+		if method == "createForumTopic" {
+			fmt.Fprint(w, `{"ok":true,"result":{"message_thread_id":42,"name":"Support","icon_color":7322096}}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	})
+
+	topic, err := bot.CreateForumTopic(CreateForumTopicConfig{ChatConfig: ChatConfig{ChatID: -100}, Name: "Support", IconColor: 0x6FB9F0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic.MessageThreadID != 42 || topic.Name != "Support" {
+		t.Errorf("unexpected topic %+v", topic)
+	}
+	if form.Get("icon_color") != "7322096" {
+		t.Errorf("expected icon_color 7322096, got %s", form.Get("icon_color"))
+	}
+
+	base := BaseForumTopic{ChatConfig: ChatConfig{ChatID: -100}, MessageThreadID: topic.MessageThreadID}
+	noIcon := ""
+	tests := []struct {
+		method string
+		config Chattable
+	}{
+		{"editForumTopic", EditForumTopicConfig{BaseForumTopic: base, IconCustomEmojiID: &noIcon}},
+		{"closeForumTopic", CloseForumTopicConfig{base}},
+		{"reopenForumTopic", ReopenForumTopicConfig{base}},
+		{"deleteForumTopic", DeleteForumTopicConfig{base}},
+	}
+
+	for _, test := range tests {
+		if _, err := bot.Request(test.config); err != nil {
+			t.Fatal(err)
+		}
+		if method != test.method {
+			t.Errorf("expected %s, got %s", test.method, method)
+		}
+		if form.Get("chat_id") != "-100" || form.Get("message_thread_id") != "42" {
+			t.Errorf("%s: unexpected params %v", test.method, form)
+		}
+		if test.method == "editForumTopic" {
+			if _, ok := form["name"]; ok {
+				t.Error("expected an empty name to be omitted")
+			}
+			if icon, ok := form["icon_custom_emoji_id"]; !ok || icon[0] != "" {
+				t.Errorf("expected an empty icon_custom_emoji_id to remove the icon, got %v", icon)
+			}
+		}
+	}
+}
+
+func TestGeneralForumTopic(t *testing.T) {
+	var method string
+	var form url.Values
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		method, form = path.Base(r.URL.Path), r.PostForm
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	})
+
+	tests := []struct {
+		method string
+		call   func() error
+	}{
+		{"closeGeneralForumTopic", func() error { return bot.CloseGeneralForumTopic(-100) }},
+		{"reopenGeneralForumTopic", func() error { return bot.ReopenGeneralForumTopic(-100) }},
+		{"hideGeneralForumTopic", func() error { return bot.HideGeneralForumTopic(-100) }},
+		{"unhideGeneralForumTopic", func() error { return bot.UnhideGeneralForumTopic(-100) }},
+	}
+
+	for _, test := range tests {
+		if err := test.call(); err != nil {
+			t.Fatal(err)
+		}
+		if method != test.method {
+			t.Errorf("expected %s, got %s", test.method, method)
+		}
+		if len(form) != 1 || form.Get("chat_id") != "-100" {
+			t.Errorf("%s: expected a single chat_id param, got %v", test.method, form)
+		}
+	}
+
+	if err := bot.EditGeneralForumTopic(-100, "Lobby"); err != nil {
+		t.Fatal(err)
+	}
+	if method != "editGeneralForumTopic" || form.Get("name") != "Lobby" {
+		t.Errorf("unexpected %s request %v", method, form)
+	}
+}
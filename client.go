@@ -90,5 +90,23 @@ func (c *Client) doRequest(method string, query url.Values) (data []byte, err er
 		return nil, err
 	}
 
+	var apiResp APIResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, err
+	}
+	if !apiResp.Ok {
+		var parameters ResponseParameters
+		if apiResp.Parameters != nil {
+			parameters = *apiResp.Parameters
+		}
+
+		return nil, &Error{
+			Code:               apiResp.ErrorCode,
+			Message:            apiResp.Description,
+			HTTPStatus:         resp.StatusCode,
+			ResponseParameters: parameters,
+		}
+	}
+
 	return body, nil
 }
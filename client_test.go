@@ -0,0 +1,66 @@
+package tgapimanager
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestClient creates a Client talking to a TLS test server running handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) Client {
+	t.Helper()
+
+	srv := httptest.NewTLSServer(handler)
+	t.Cleanup(srv.Close)
+
+	client := New(strings.TrimPrefix(srv.URL, "https://"), "token")
+	client.Client = *srv.Client()
+
+	return client
+}
+
+func TestClientSendMessageFloodError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bottoken/sendMessage" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 7","parameters":{"retry_after":7}}`)
+	})
+
+	err := client.SendMessage(1, "hello")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.HasPrefix(err.Error(), "can't send a message: can't do a request: ") {
+		t.Errorf("expected the error to be wrapped, got %q", err)
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected errors.As to find the API error in %v", err)
+	}
+	if !apiErr.IsFloodError() || apiErr.RetryAfter != 7 || apiErr.HTTPStatus != http.StatusTooManyRequests {
+		t.Errorf("unexpected API error %+v", apiErr)
+	}
+}
+
+func TestClientUpdates(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("offset"); got != "5" {
+			t.Errorf("expected offset 5, got %s", got)
+		}
+		fmt.Fprint(w, `{"ok":true,"result":[{"update_id":5,"message":{"message_id":1,"date":0,"chat":{"id":1},"text":"hi"}}]}`)
+	})
+
+	updates, err := client.Updates(5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 1 || updates[0].Message == nil || updates[0].Message.Text != "hi" {
+		t.Errorf("unexpected updates %+v", updates)
+	}
+}
@@ -0,0 +1,64 @@
+package tgapimanager
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// CommandHandler handles a bot command and describes it for the command menu
+// shown by Telegram clients.
+type CommandHandler interface {
+	// Description of the command, 3-256 characters.
+	Description() string
+	// HandleCommand handles a message sent with the command.
+	HandleCommand(bot *BotAPI, message *Message) error
+}
+
+// CommandsFromHandlers builds the command list for SetMyCommandsConfig from
+// handlers, keyed by command without the leading slash.
+//
+// Commands are sorted by name, so the list only changes when the handlers do.
+// An error is returned if a command or its description isn't accepted by
+// Telegram.
+func CommandsFromHandlers(handlers map[string]CommandHandler) ([]BotCommand, error) {
+	commands := make([]BotCommand, 0, len(handlers))
+
+	for command, handler := range handlers {
+		if err := validateCommand(command); err != nil {
+			return nil, err
+		}
+
+		description := handler.Description()
+		if n := utf8.RuneCountInString(description); n < 3 || n > 256 {
+			return nil, fmt.Errorf("description of command %q must be 3-256 characters, got %d", command, n)
+		}
+
+		commands = append(commands, BotCommand{
+			Command:     command,
+			Description: description,
+		})
+	}
+
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Command < commands[j].Command
+	})
+
+	return commands, nil
+}
+
+// validateCommand checks that command is 1-32 lowercase English letters,
+// digits and underscores.
+func validateCommand(command string) error {
+	if len(command) == 0 || len(command) > 32 {
+		return fmt.Errorf("command %q must be 1-32 characters", command)
+	}
+
+	for _, r := range command {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+			return fmt.Errorf("command %q can only contain lowercase English letters, digits and underscores", command)
+		}
+	}
+
+	return nil
+}
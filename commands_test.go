@@ -0,0 +1,47 @@
+package tgapimanager
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testCommand string
+
+func (c testCommand) Description() string { return string(c) }
+
+func (c testCommand) HandleCommand(bot *BotAPI, message *Message) error { return nil }
+
+func TestCommandsFromHandlers(t *testing.T) {
+	commands, err := CommandsFromHandlers(map[string]CommandHandler{
+		"start": testCommand("Start the bot"),
+		"help":  testCommand("Show help"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []BotCommand{
+		{Command: "help", Description: "Show help"},
+		{Command: "start", Description: "Start the bot"},
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("expected %v, got %v", expected, commands)
+	}
+}
+
+func TestCommandsFromHandlersInvalid(t *testing.T) {
+	tests := map[string]map[string]CommandHandler{
+		"uppercase command": {"Start": testCommand("Start the bot")},
+		"slash":             {"/start": testCommand("Start the bot")},
+		"short description": {"start": testCommand("Go")},
+		"empty command":     {"": testCommand("Start the bot")},
+	}
+
+	for name, handlers := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := CommandsFromHandlers(handlers); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
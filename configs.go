@@ -1,8 +1,15 @@
 package tgapimanager
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"unicode/utf8"
 )
 
 const (
@@ -13,36 +20,236 @@ const (
 	FileEndpoint = "https://api.telegram.org/file/bot%s/%s"
 )
 
+// MaxMessageLength is the maximum length of a text message in UTF-16 code
+// units, after entities parsing.
+const MaxMessageLength = 4096
+
+// Constant values for update types, for use in AllowedUpdates.
+const (
+	// UpdateTypeMessage is new incoming message of any kind — text, photo, sticker, etc.
+	UpdateTypeMessage = "message"
+	// UpdateTypeEditedMessage is new version of a message that is known to the bot and was edited
+	UpdateTypeEditedMessage = "edited_message"
+	// UpdateTypeChannelPost is new incoming channel post of any kind — text, photo, sticker, etc.
+	UpdateTypeChannelPost = "channel_post"
+	// UpdateTypeEditedChannelPost is new version of a channel post that is known to the bot and was edited
+	UpdateTypeEditedChannelPost = "edited_channel_post"
+	// UpdateTypeInlineQuery is new incoming inline query
+	UpdateTypeInlineQuery = "inline_query"
+	// UpdateTypeChosenInlineResult is the result of an inline query that was
+	// chosen by a user and sent to their chat partner
+	UpdateTypeChosenInlineResult = "chosen_inline_result"
+	// UpdateTypeCallbackQuery is new incoming callback query
+	UpdateTypeCallbackQuery = "callback_query"
+	// UpdateTypeShippingQuery is new incoming shipping query. Only for invoices with flexible price
+	UpdateTypeShippingQuery = "shipping_query"
+	// UpdateTypePreCheckoutQuery is new incoming pre-checkout query. Contains full information about checkout
+	UpdateTypePreCheckoutQuery = "pre_checkout_query"
+	// UpdateTypePoll is new poll state. Bots receive only updates about stopped polls and polls
+	// which are sent by the bot
+	UpdateTypePoll = "poll"
+	// UpdateTypePollAnswer is when user changed their answer in a non-anonymous poll
+	UpdateTypePollAnswer = "poll_answer"
+	// UpdateTypeMyChatMember is when the bot's chat member status was updated in a chat
+	UpdateTypeMyChatMember = "my_chat_member"
+	// UpdateTypeChatMember is when a chat member's status was updated in a chat,
+	// only received when explicitly allowed
+	UpdateTypeChatMember = "chat_member"
+	// UpdateTypeChatJoinRequest is a request to join the chat
+	UpdateTypeChatJoinRequest = "chat_join_request"
+	// UpdateTypeMessageReaction is a reaction to a message changed by a user,
+	// only received when explicitly allowed
+	UpdateTypeMessageReaction = "message_reaction"
+	// UpdateTypeMessageReactionCount is anonymous reaction changes on a message,
+	// only received when explicitly allowed
+	UpdateTypeMessageReactionCount = "message_reaction_count"
+	// UpdateTypePurchasedPaidMedia is a user purchasing paid media with a
+	// non-empty payload sent by the bot, only received when explicitly allowed
+	UpdateTypePurchasedPaidMedia = "purchased_paid_media"
+)
+
+// updateTypes are all known update types, in the order the Bot API documents
+// them.
+var updateTypes = []string{
+	UpdateTypeMessage,
+	UpdateTypeEditedMessage,
+	UpdateTypeChannelPost,
+	UpdateTypeEditedChannelPost,
+	UpdateTypeInlineQuery,
+	UpdateTypeChosenInlineResult,
+	UpdateTypeCallbackQuery,
+	UpdateTypeShippingQuery,
+	UpdateTypePreCheckoutQuery,
+	UpdateTypePoll,
+	UpdateTypePollAnswer,
+	UpdateTypeMyChatMember,
+	UpdateTypeChatMember,
+	UpdateTypeChatJoinRequest,
+	UpdateTypeMessageReaction,
+	UpdateTypeMessageReactionCount,
+	UpdateTypePurchasedPaidMedia,
+}
+
+// Constant values for ParseMode in MessageConfig
+const (
+	ModeMarkdown   = "Markdown"
+	ModeMarkdownV2 = "MarkdownV2"
+	ModeHTML       = "HTML"
+)
+
+// Constant values for Chat.Type.
+const (
+	ChatTypePrivate    = "private"
+	ChatTypeGroup      = "group"
+	ChatTypeSuperGroup = "supergroup"
+	ChatTypeChannel    = "channel"
+)
+
+// Constant values for MessageOrigin.Type.
+const (
+	MessageOriginTypeUser       = "user"
+	MessageOriginTypeHiddenUser = "hidden_user"
+	MessageOriginTypeChat       = "chat"
+	MessageOriginTypeChannel    = "channel"
+)
+
 // BaseChat is base type for all chat config types.
 type BaseChat struct {
 	ChatID                   int64 // required
+	MessageThreadID          int
 	ChannelUsername          string
 	ReplyToMessageID         int
+	ReplyParameters          *ReplyParameters
 	ReplyMarkup              interface{}
 	DisableNotification      bool
+	ProtectContent           bool
 	AllowSendingWithoutReply bool
 }
+
+// BaseFile is a base type for all file config types.
+type BaseFile struct {
+	BaseChat
+	File RequestFileData
+}
+
+func (file BaseFile) params() (Params, error) {
+	return file.BaseChat.params()
+}
+
+// MessageConfig contains information about a SendMessage request.
+//
+// DisableWebPagePreview is ignored when LinkPreviewOptions is set.
 type MessageConfig struct {
 	BaseChat
 	Text                  string
 	ParseMode             string
 	Entities              []MessageEntity
 	DisableWebPagePreview bool
+	LinkPreviewOptions    *LinkPreviewOptions
+}
+
+// addChatID sets chat_id to chatID, or to username if chatID is zero. It
+// fails if neither is set, as Telegram's error for that is unclear.
+func addChatID(params Params, chatID int64, username string) error {
+	params.AddFirstValid("chat_id", chatID, username)
+	if _, ok := params["chat_id"]; !ok {
+		return errors.New("chat_id or channel username required")
+	}
+
+	return nil
 }
 
 func (chat *BaseChat) params() (Params, error) {
 	params := make(Params)
 
-	params.AddFirstValid("chat_id", chat.ChatID, chat.ChannelUsername)
+	if err := addChatID(params, chat.ChatID, chat.ChannelUsername); err != nil {
+		return params, err
+	}
+
+	params.AddNonZero("message_thread_id", chat.MessageThreadID)
 	params.AddNonZero("reply_to_message_id", chat.ReplyToMessageID)
 	params.AddBool("disable_notification", chat.DisableNotification)
+	params.AddBool("protect_content", chat.ProtectContent)
 	params.AddBool("allow_sending_without_reply", chat.AllowSendingWithoutReply)
 
+	if err := params.AddInterface("reply_parameters", chat.ReplyParameters); err != nil {
+		return params, err
+	}
+
+	if err := validateReplyMarkup(chat.ReplyMarkup); err != nil {
+		return params, err
+	}
+
 	err := params.AddInterface("reply_markup", chat.ReplyMarkup)
 
 	return params, err
 }
 
+// validateReplyMarkup checks an inline keyboard before it is sent. Other
+// kinds of markup are left for Telegram to check.
+func validateReplyMarkup(markup interface{}) error {
+	switch m := markup.(type) {
+	case InlineKeyboardMarkup:
+		return m.Validate()
+	case *InlineKeyboardMarkup:
+		if m != nil {
+			return m.Validate()
+		}
+	}
+
+	return nil
+}
+
+// withDefaultParseMode returns c with its ParseMode set to parseMode, if c
+// has a text or caption that is formatted by neither a ParseMode nor
+// entities. Other requests are returned as is.
+func withDefaultParseMode(c Chattable, parseMode string) Chattable {
+	useDefault := func(current string, entities []MessageEntity) string {
+		if current == "" && len(entities) == 0 {
+			return parseMode
+		}
+
+		return current
+	}
+
+	switch config := c.(type) {
+	case MessageConfig:
+		config.ParseMode = useDefault(config.ParseMode, config.Entities)
+		return config
+	case EditMessageTextConfig:
+		config.ParseMode = useDefault(config.ParseMode, config.Entities)
+		return config
+	case EditMessageCaptionConfig:
+		config.ParseMode = useDefault(config.ParseMode, config.CaptionEntities)
+		return config
+	case CopyMessageConfig:
+		if config.Caption != "" {
+			config.ParseMode = useDefault(config.ParseMode, config.CaptionEntities)
+		}
+		return config
+	case PhotoConfig:
+		config.ParseMode = useDefault(config.ParseMode, config.CaptionEntities)
+		return config
+	case DocumentConfig:
+		config.ParseMode = useDefault(config.ParseMode, config.CaptionEntities)
+		return config
+	case AudioConfig:
+		config.ParseMode = useDefault(config.ParseMode, config.CaptionEntities)
+		return config
+	case VideoConfig:
+		config.ParseMode = useDefault(config.ParseMode, config.CaptionEntities)
+		return config
+	case VoiceConfig:
+		config.ParseMode = useDefault(config.ParseMode, config.CaptionEntities)
+		return config
+	case AnimationConfig:
+		config.ParseMode = useDefault(config.ParseMode, config.CaptionEntities)
+		return config
+	default:
+		return c
+	}
+}
+
 func (config MessageConfig) params() (Params, error) {
 	params, err := config.BaseChat.params()
 	if err != nil {
@@ -50,8 +257,10 @@ func (config MessageConfig) params() (Params, error) {
 	}
 
 	params.AddNonEmpty("text", config.Text)
-	params.AddBool("disable_web_page_preview", config.DisableWebPagePreview)
 	params.AddNonEmpty("parse_mode", config.ParseMode)
+	if err := addLinkPreview(params, config.LinkPreviewOptions, config.DisableWebPagePreview); err != nil {
+		return params, err
+	}
 	err = params.AddInterface("entities", config.Entities)
 
 	return params, err
@@ -61,6 +270,17 @@ func (config MessageConfig) method() string {
 	return "sendMessage"
 }
 
+// addLinkPreview adds the link preview options of a text message, falling
+// back to the legacy disable_web_page_preview flag when options is nil.
+func addLinkPreview(params Params, options *LinkPreviewOptions, disableWebPagePreview bool) error {
+	if options == nil {
+		params.AddBool("disable_web_page_preview", disableWebPagePreview)
+		return nil
+	}
+
+	return params.AddInterface("link_preview_options", options)
+}
+
 // Chattable is any config type that can be sent.
 type Chattable interface {
 	params() (Params, error)
@@ -94,7 +314,134 @@ type RequestFileData interface {
 	SendData() string
 }
 
+// FileBytes contains information about a set of bytes to upload
+// as a File.
+type FileBytes struct {
+	Name  string
+	Bytes []byte
+}
+
+func (fb FileBytes) NeedsUpload() bool {
+	return true
+}
+
+func (fb FileBytes) UploadData() (string, io.Reader, error) {
+	return fb.Name, bytes.NewReader(fb.Bytes), nil
+}
+
+func (fb FileBytes) SendData() string {
+	panic("FileBytes must be uploaded")
+}
+
+// FileReader contains information about a reader to upload as a File.
+type FileReader struct {
+	Name   string
+	Reader io.Reader
+}
+
+func (fr FileReader) NeedsUpload() bool {
+	return true
+}
+
+func (fr FileReader) UploadData() (string, io.Reader, error) {
+	return fr.Name, fr.Reader, nil
+}
+
+func (fr FileReader) SendData() string {
+	panic("FileReader must be uploaded")
+}
+
+// FilePath is a path to a local file.
+type FilePath string
+
+func (fp FilePath) NeedsUpload() bool {
+	return true
+}
+
+func (fp FilePath) UploadData() (string, io.Reader, error) {
+	fileHandle, err := os.Open(string(fp))
+	if err != nil {
+		return "", nil, err
+	}
+
+	name := filepath.Base(string(fp))
+
+	return name, fileHandle, err
+}
+
+func (fp FilePath) SendData() string {
+	panic("FilePath must be uploaded")
+}
+
+// FileURL is a URL to use as a file for a request.
+type FileURL string
+
+func (fu FileURL) NeedsUpload() bool {
+	return false
+}
+
+func (fu FileURL) UploadData() (string, io.Reader, error) {
+	panic("FileURL cannot be uploaded")
+}
+
+func (fu FileURL) SendData() string {
+	return string(fu)
+}
+
+// FileID is an ID of a file already uploaded to Telegram.
+type FileID string
+
+func (fi FileID) NeedsUpload() bool {
+	return false
+}
+
+func (fi FileID) UploadData() (string, io.Reader, error) {
+	panic("FileID cannot be uploaded")
+}
+
+func (fi FileID) SendData() string {
+	return string(fi)
+}
+
+// fileAttach is an internal file type used for processed media groups.
+type fileAttach string
+
+func (fa fileAttach) NeedsUpload() bool {
+	return false
+}
+
+func (fa fileAttach) UploadData() (string, io.Reader, error) {
+	panic("fileAttach cannot be uploaded")
+}
+
+func (fa fileAttach) SendData() string {
+	return string(fa)
+}
+
+// FileConfig has information about a file hosted on Telegram.
+type FileConfig struct {
+	FileID string
+}
+
+func (FileConfig) method() string {
+	return "getFile"
+}
+
+func (config FileConfig) params() (Params, error) {
+	params := make(Params)
+
+	params["file_id"] = config.FileID
+
+	return params, nil
+}
+
 // UpdateConfig contains information about a GetUpdates request.
+//
+// AllowedUpdates lists the update types to receive, such as
+// UpdateTypeMessage and UpdateTypeCallbackQuery. A nil list keeps whatever
+// Telegram used previously, while an empty non-nil list requests all update
+// types except those that must be explicitly allowed, such as
+// UpdateTypeChatMember.
 type UpdateConfig struct {
 	Offset         int
 	Limit          int
@@ -112,7 +459,11 @@ func (config UpdateConfig) params() (Params, error) {
 	params.AddNonZero("offset", config.Offset)
 	params.AddNonZero("limit", config.Limit)
 	params.AddNonZero("timeout", config.Timeout)
-	params.AddInterface("allowed_updates", config.AllowedUpdates)
+	if config.AllowedUpdates != nil {
+		if err := params.AddInterface("allowed_updates", config.AllowedUpdates); err != nil {
+			return params, err
+		}
+	}
 
 	return params, nil
 }
@@ -196,6 +547,10 @@ func (edit BaseEdit) params() (Params, error) {
 		params.AddNonZero("message_id", edit.MessageID)
 	}
 
+	if err := validateReplyMarkup(edit.ReplyMarkup); err != nil {
+		return params, err
+	}
+
 	err := params.AddInterface("reply_markup", edit.ReplyMarkup)
 
 	return params, err
@@ -214,7 +569,56 @@ func (StopPollConfig) method() string {
 	return "stopPoll"
 }
 
+// GetGameHighScoresConfig allows you to fetch the high scores for a game.
+//
+// The game message is addressed like in BaseEdit, either by ChatID (or
+// ChannelUsername) and MessageID, or by InlineMessageID, but not both.
+type GetGameHighScoresConfig struct {
+	UserID          int64 // required
+	ChatID          int64
+	ChannelUsername string
+	MessageID       int
+	InlineMessageID string
+}
+
+func (config GetGameHighScoresConfig) params() (Params, error) {
+	params := make(Params)
+
+	hasChat := config.ChatID != 0 || config.ChannelUsername != ""
+	hasChatFields := hasChat || config.MessageID != 0
+	hasInlineMessage := config.InlineMessageID != ""
+
+	switch {
+	case hasChatFields && hasInlineMessage:
+		return params, errors.New("only one of chat_id with message_id or inline_message_id can be set")
+	case !hasChatFields && !hasInlineMessage:
+		return params, errors.New("chat_id with message_id or inline_message_id required")
+	case hasChatFields && (!hasChat || config.MessageID == 0):
+		return params, errors.New("chat_id and message_id must be set together")
+	case hasInlineMessage:
+		params["inline_message_id"] = config.InlineMessageID
+	default:
+		params.AddFirstValid("chat_id", config.ChatID, config.ChannelUsername)
+		params.AddNonZero("message_id", config.MessageID)
+	}
+
+	params.AddNonZero64("user_id", config.UserID)
+
+	return params, nil
+}
+
+func (config GetGameHighScoresConfig) method() string {
+	return "getGameHighScores"
+}
+
 // LocationConfig contains information about a SendLocation request.
+//
+// The optional fields are omitted when zero, which Telegram treats as
+// unspecified: a HorizontalAccuracy of 0 means an unknown accuracy, a
+// LivePeriod of 0 sends a static location, and Heading is only valid from 1
+// to 360, so a heading of 0 can't be sent. Heading and ProximityAlertRadius
+// only apply to live locations, and a ProximityAlertRadius without a
+// LivePeriod is an error.
 type LocationConfig struct {
 	BaseChat
 	Latitude             float64 // required
@@ -226,10 +630,16 @@ type LocationConfig struct {
 }
 
 func (config LocationConfig) params() (Params, error) {
+	if config.ProximityAlertRadius != 0 && config.LivePeriod <= 0 {
+		return nil, errors.New("proximity alerts are only supported for live locations, set LivePeriod")
+	}
+
 	params, err := config.BaseChat.params()
 
-	params.AddNonZeroFloat("latitude", config.Latitude)
-	params.AddNonZeroFloat("longitude", config.Longitude)
+	// A latitude or longitude of 0 is a valid coordinate, so these are
+	// always sent.
+	params["latitude"] = strconv.FormatFloat(config.Latitude, 'f', 6, 64)
+	params["longitude"] = strconv.FormatFloat(config.Longitude, 'f', 6, 64)
 	params.AddNonZeroFloat("horizontal_accuracy", config.HorizontalAccuracy)
 	params.AddNonZero("live_period", config.LivePeriod)
 	params.AddNonZero("heading", config.Heading)
@@ -255,8 +665,8 @@ type EditMessageLiveLocationConfig struct {
 func (config EditMessageLiveLocationConfig) params() (Params, error) {
 	params, err := config.BaseEdit.params()
 
-	params.AddNonZeroFloat("latitude", config.Latitude)
-	params.AddNonZeroFloat("longitude", config.Longitude)
+	params["latitude"] = strconv.FormatFloat(config.Latitude, 'f', 6, 64)
+	params["longitude"] = strconv.FormatFloat(config.Longitude, 'f', 6, 64)
 	params.AddNonZeroFloat("horizontal_accuracy", config.HorizontalAccuracy)
 	params.AddNonZero("heading", config.Heading)
 	params.AddNonZero("proximity_alert_radius", config.ProximityAlertRadius)
@@ -297,8 +707,8 @@ type VenueConfig struct {
 func (config VenueConfig) params() (Params, error) {
 	params, err := config.BaseChat.params()
 
-	params.AddNonZeroFloat("latitude", config.Latitude)
-	params.AddNonZeroFloat("longitude", config.Longitude)
+	params["latitude"] = strconv.FormatFloat(config.Latitude, 'f', 6, 64)
+	params["longitude"] = strconv.FormatFloat(config.Longitude, 'f', 6, 64)
 	params["title"] = config.Title
 	params["address"] = config.Address
 	params.AddNonEmpty("foursquare_id", config.FoursquareID)
@@ -322,7 +732,26 @@ type ContactConfig struct {
 	VCard       string
 }
 
+func (config ContactConfig) params() (Params, error) {
+	params, err := config.BaseChat.params()
+
+	params["phone_number"] = config.PhoneNumber
+	params["first_name"] = config.FirstName
+
+	params.AddNonEmpty("last_name", config.LastName)
+	params.AddNonEmpty("vcard", config.VCard)
+
+	return params, err
+}
+
+func (config ContactConfig) method() string {
+	return "sendContact"
+}
+
 // WebhookConfig contains information about a SetWebhook request.
+//
+// SecretToken is sent by Telegram in every webhook request, set the same
+// value as BotAPI.SecretToken so HandleUpdate rejects requests without it.
 type WebhookConfig struct {
 	URL                *url.URL
 	Certificate        RequestFileData
@@ -330,6 +759,7 @@ type WebhookConfig struct {
 	MaxConnections     int
 	AllowedUpdates     []string
 	DropPendingUpdates bool
+	SecretToken        string
 }
 
 func (config WebhookConfig) method() string {
@@ -345,8 +775,13 @@ func (config WebhookConfig) params() (Params, error) {
 
 	params.AddNonEmpty("ip_address", config.IPAddress)
 	params.AddNonZero("max_connections", config.MaxConnections)
-	err := params.AddInterface("allowed_updates", config.AllowedUpdates)
 	params.AddBool("drop_pending_updates", config.DropPendingUpdates)
+	params.AddNonEmpty("secret_token", config.SecretToken)
+
+	var err error
+	if config.AllowedUpdates != nil {
+		err = params.AddInterface("allowed_updates", config.AllowedUpdates)
+	}
 
 	return params, err
 }
@@ -380,12 +815,15 @@ func (config DeleteWebhookConfig) params() (Params, error) {
 }
 
 // EditMessageTextConfig allows you to modify the text in a message.
+//
+// DisableWebPagePreview is ignored when LinkPreviewOptions is set.
 type EditMessageTextConfig struct {
 	BaseEdit
 	Text                  string
 	ParseMode             string
 	Entities              []MessageEntity
 	DisableWebPagePreview bool
+	LinkPreviewOptions    *LinkPreviewOptions
 }
 
 func (config EditMessageTextConfig) params() (Params, error) {
@@ -396,7 +834,9 @@ func (config EditMessageTextConfig) params() (Params, error) {
 
 	params["text"] = config.Text
 	params.AddNonEmpty("parse_mode", config.ParseMode)
-	params.AddBool("disable_web_page_preview", config.DisableWebPagePreview)
+	if err := addLinkPreview(params, config.LinkPreviewOptions, config.DisableWebPagePreview); err != nil {
+		return params, err
+	}
 	err = params.AddInterface("entities", config.Entities)
 
 	return params, err
@@ -455,3 +895,1467 @@ func (config EditMessageReplyMarkupConfig) params() (Params, error) {
 func (config EditMessageReplyMarkupConfig) method() string {
 	return "editMessageReplyMarkup"
 }
+
+// MediaGroupConfig allows you to send a group of media.
+//
+// Media consist of InputMedia items (InputMediaPhoto, InputMediaVideo,
+// InputMediaAudio, InputMediaDocument).
+type MediaGroupConfig struct {
+	ChatID          int64
+	MessageThreadID int
+	ChannelUsername string
+
+	Media               []interface{}
+	DisableNotification bool
+	ReplyToMessageID    int
+}
+
+func (config MediaGroupConfig) method() string {
+	return "sendMediaGroup"
+}
+
+func (config MediaGroupConfig) params() (Params, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	params := make(Params)
+
+	if err := addChatID(params, config.ChatID, config.ChannelUsername); err != nil {
+		return params, err
+	}
+	params.AddNonZero("message_thread_id", config.MessageThreadID)
+	params.AddBool("disable_notification", config.DisableNotification)
+	params.AddNonZero("reply_to_message_id", config.ReplyToMessageID)
+
+	err := params.AddInterface("media", prepareInputMediaForParams(config.Media))
+
+	return params, err
+}
+
+func (config MediaGroupConfig) files() []RequestFile {
+	return prepareInputMediaForFiles(config.Media)
+}
+
+// Validate checks the media group against the constraints Telegram enforces
+// on albums.
+//
+// A group must contain 2-10 items. Photos and videos may be mixed freely, but
+// documents may only be grouped with other documents and audio files only
+// with other audio files.
+func (config MediaGroupConfig) Validate() error {
+	if len(config.Media) < 2 || len(config.Media) > 10 {
+		return fmt.Errorf("media group must contain 2-10 items, got %d", len(config.Media))
+	}
+
+	first, err := inputMediaType(config.Media[0])
+	if err != nil {
+		return err
+	}
+
+	for _, media := range config.Media[1:] {
+		kind, err := inputMediaType(media)
+		if err != nil {
+			return err
+		}
+
+		if kind == first {
+			continue
+		}
+
+		if kind == "document" || first == "document" {
+			return fmt.Errorf("media group can't mix %s and %s: documents must be grouped separately", first, kind)
+		}
+
+		if kind == "audio" || first == "audio" {
+			return fmt.Errorf("media group can't mix %s and %s: audio must be grouped separately", first, kind)
+		}
+	}
+
+	return nil
+}
+
+// inputMediaType returns the kind of media an InputMedia item contains.
+func inputMediaType(media interface{}) (string, error) {
+	switch media.(type) {
+	case InputMediaPhoto, *InputMediaPhoto:
+		return "photo", nil
+	case InputMediaVideo, *InputMediaVideo:
+		return "video", nil
+	case InputMediaAudio, *InputMediaAudio:
+		return "audio", nil
+	case InputMediaDocument, *InputMediaDocument:
+		return "document", nil
+	default:
+		return "", fmt.Errorf("unsupported media group item %T", media)
+	}
+}
+
+// AnswerInlineQueryConfig contains information about an answerInlineQuery
+// request.
+//
+// Results are inline query results, such as InlineQueryResultArticle and
+// InlineQueryResultPhoto.
+type AnswerInlineQueryConfig struct {
+	InlineQueryID     string        // required
+	Results           []interface{} // required
+	CacheTime         int
+	IsPersonal        bool
+	NextOffset        string
+	SwitchPMText      string
+	SwitchPMParameter string
+}
+
+func (config AnswerInlineQueryConfig) method() string {
+	return "answerInlineQuery"
+}
+
+func (config AnswerInlineQueryConfig) params() (Params, error) {
+	params := make(Params)
+
+	params["inline_query_id"] = config.InlineQueryID
+	params.AddNonZero("cache_time", config.CacheTime)
+	params.AddBool("is_personal", config.IsPersonal)
+	params.AddNonEmpty("next_offset", config.NextOffset)
+	params.AddNonEmpty("switch_pm_text", config.SwitchPMText)
+	params.AddNonEmpty("switch_pm_parameter", config.SwitchPMParameter)
+
+	results := config.Results
+	if results == nil {
+		results = []interface{}{}
+	}
+	err := params.AddInterface("results", results)
+
+	return params, err
+}
+
+// SavePreparedInlineMessageConfig stores a message that can be sent by a user
+// of a Mini App.
+//
+// Result is an inline query result, such as InlineQueryResultArticle. At
+// least one of the Allow fields should be set to choose the chats the
+// message can be sent to.
+type SavePreparedInlineMessageConfig struct {
+	UserID            int64       // required
+	Result            interface{} // required
+	AllowUserChats    bool
+	AllowBotChats     bool
+	AllowGroupChats   bool
+	AllowChannelChats bool
+}
+
+func (config SavePreparedInlineMessageConfig) method() string {
+	return "savePreparedInlineMessage"
+}
+
+func (config SavePreparedInlineMessageConfig) params() (Params, error) {
+	params := make(Params)
+
+	params.AddNonZero64("user_id", config.UserID)
+	if err := params.AddInterface("result", config.Result); err != nil {
+		return params, err
+	}
+	params.AddBool("allow_user_chats", config.AllowUserChats)
+	params.AddBool("allow_bot_chats", config.AllowBotChats)
+	params.AddBool("allow_group_chats", config.AllowGroupChats)
+	params.AddBool("allow_channel_chats", config.AllowChannelChats)
+
+	return params, nil
+}
+
+// ChatConfig is a base type for all chat identifiers.
+type ChatConfig struct {
+	ChatID             int64
+	SuperGroupUsername string
+}
+
+func (config ChatConfig) params() (Params, error) {
+	params := make(Params)
+
+	err := addChatID(params, config.ChatID, config.SuperGroupUsername)
+
+	return params, err
+}
+
+// GetChatConfig contains information about a getChat request.
+type GetChatConfig struct {
+	ChatConfig
+}
+
+func (config GetChatConfig) method() string {
+	return "getChat"
+}
+
+// ChatAdministratorsConfig contains information about a getChatAdministrators
+// request.
+type ChatAdministratorsConfig struct {
+	ChatConfig
+}
+
+func (config ChatAdministratorsConfig) method() string {
+	return "getChatAdministrators"
+}
+
+// ChatMemberConfig contains information about a user in a chat for use
+// with administrative functions such as restricting a user.
+type ChatMemberConfig struct {
+	ChatID             int64
+	SuperGroupUsername string
+	ChannelUsername    string
+	UserID             int64
+}
+
+// GetChatMemberConfig contains information about a getChatMember request.
+type GetChatMemberConfig struct {
+	ChatMemberConfig
+}
+
+func (config GetChatMemberConfig) method() string {
+	return "getChatMember"
+}
+
+func (config GetChatMemberConfig) params() (Params, error) {
+	params := make(Params)
+
+	params.AddFirstValid("chat_id", config.ChatID, config.SuperGroupUsername, config.ChannelUsername)
+	params.AddNonZero64("user_id", config.UserID)
+
+	return params, nil
+}
+
+// BanChatMemberConfig contains extra fields to ban a user.
+//
+// UntilDate is when the user is unbanned, in Unix time. Leave it zero, or
+// set it to less than 30 seconds or more than 366 days from now, to ban the
+// user forever.
+type BanChatMemberConfig struct {
+	ChatMemberConfig
+	UntilDate      int64
+	RevokeMessages bool
+}
+
+func (config BanChatMemberConfig) method() string {
+	return "banChatMember"
+}
+
+func (config BanChatMemberConfig) params() (Params, error) {
+	params := make(Params)
+
+	params.AddFirstValid("chat_id", config.ChatID, config.SuperGroupUsername, config.ChannelUsername)
+	params.AddNonZero64("user_id", config.UserID)
+	params.AddNonZero64("until_date", config.UntilDate)
+	params.AddBool("revoke_messages", config.RevokeMessages)
+
+	return params, nil
+}
+
+// UnbanChatMemberConfig allows you to unban a user.
+//
+// Unbanning a user who is in the chat removes them from it, unless
+// OnlyIfBanned is set.
+type UnbanChatMemberConfig struct {
+	ChatMemberConfig
+	OnlyIfBanned bool
+}
+
+func (config UnbanChatMemberConfig) method() string {
+	return "unbanChatMember"
+}
+
+func (config UnbanChatMemberConfig) params() (Params, error) {
+	params := make(Params)
+
+	params.AddFirstValid("chat_id", config.ChatID, config.SuperGroupUsername, config.ChannelUsername)
+	params.AddNonZero64("user_id", config.UserID)
+	params.AddBool("only_if_banned", config.OnlyIfBanned)
+
+	return params, nil
+}
+
+// ApproveChatJoinRequestConfig allows you to approve a chat join request. The
+// bot must be an administrator with the can_invite_users right.
+type ApproveChatJoinRequestConfig struct {
+	ChatConfig
+	UserID int64
+}
+
+func (config ApproveChatJoinRequestConfig) method() string {
+	return "approveChatJoinRequest"
+}
+
+func (config ApproveChatJoinRequestConfig) params() (Params, error) {
+	params, err := config.ChatConfig.params()
+	if err != nil {
+		return params, err
+	}
+
+	params.AddNonZero64("user_id", config.UserID)
+
+	return params, nil
+}
+
+// DeclineChatJoinRequestConfig allows you to decline a chat join request. The
+// bot must be an administrator with the can_invite_users right.
+type DeclineChatJoinRequestConfig struct {
+	ChatConfig
+	UserID int64
+}
+
+func (config DeclineChatJoinRequestConfig) method() string {
+	return "declineChatJoinRequest"
+}
+
+func (config DeclineChatJoinRequestConfig) params() (Params, error) {
+	params, err := config.ChatConfig.params()
+	if err != nil {
+		return params, err
+	}
+
+	params.AddNonZero64("user_id", config.UserID)
+
+	return params, nil
+}
+
+// RestrictChatMemberConfig contains fields to restrict members of chat.
+//
+// IndependentChatPermissions controls how the granular media permissions are
+// applied. When true, CanSendPhotos, CanSendVideos and the other media flags
+// are set exactly as given. When false, Telegram derives them from
+// CanSendOtherMessages and CanAddWebPagePreviews. Leave it nil to use
+// Telegram's default.
+type RestrictChatMemberConfig struct {
+	ChatMemberConfig
+	UntilDate                  int64
+	Permissions                *ChatPermissions
+	IndependentChatPermissions *bool
+}
+
+func (config RestrictChatMemberConfig) method() string {
+	return "restrictChatMember"
+}
+
+func (config RestrictChatMemberConfig) params() (Params, error) {
+	params := make(Params)
+
+	params.AddFirstValid("chat_id", config.ChatID, config.SuperGroupUsername, config.ChannelUsername)
+	params.AddNonZero64("user_id", config.UserID)
+	params.AddNonZero64("until_date", config.UntilDate)
+	params.AddBoolPtr("use_independent_chat_permissions", config.IndependentChatPermissions)
+
+	err := params.AddInterface("permissions", config.Permissions)
+
+	return params, err
+}
+
+// SetChatPermissionsConfig allows you to set default permissions for the
+// members in a group. The bot must be an administrator and have rights to
+// restrict members.
+//
+// IndependentChatPermissions behaves as in RestrictChatMemberConfig.
+type SetChatPermissionsConfig struct {
+	ChatConfig
+	Permissions                *ChatPermissions
+	IndependentChatPermissions *bool
+}
+
+func (config SetChatPermissionsConfig) method() string {
+	return "setChatPermissions"
+}
+
+func (config SetChatPermissionsConfig) params() (Params, error) {
+	params, err := config.ChatConfig.params()
+	if err != nil {
+		return params, err
+	}
+
+	params.AddBoolPtr("use_independent_chat_permissions", config.IndependentChatPermissions)
+
+	err = params.AddInterface("permissions", config.Permissions)
+
+	return params, err
+}
+
+// SetChatTitleConfig allows you to set the title of something other than a
+// private chat. Title must be 1-255 characters.
+type SetChatTitleConfig struct {
+	ChatConfig
+	Title string
+}
+
+func (config SetChatTitleConfig) method() string {
+	return "setChatTitle"
+}
+
+func (config SetChatTitleConfig) params() (Params, error) {
+	if n := utf8.RuneCountInString(config.Title); n == 0 || n > 255 {
+		return nil, fmt.Errorf("chat title must be 1-255 characters, got %d", n)
+	}
+
+	params, err := config.ChatConfig.params()
+	if err != nil {
+		return params, err
+	}
+
+	params["title"] = config.Title
+
+	return params, nil
+}
+
+// SetChatDescriptionConfig allows you to set the description of a supergroup
+// or channel. Description must be at most 255 characters, leave it empty to
+// remove the description.
+type SetChatDescriptionConfig struct {
+	ChatConfig
+	Description string
+}
+
+func (config SetChatDescriptionConfig) method() string {
+	return "setChatDescription"
+}
+
+func (config SetChatDescriptionConfig) params() (Params, error) {
+	if n := utf8.RuneCountInString(config.Description); n > 255 {
+		return nil, fmt.Errorf("chat description must be at most 255 characters, got %d", n)
+	}
+
+	params, err := config.ChatConfig.params()
+	if err != nil {
+		return params, err
+	}
+
+	params["description"] = config.Description
+
+	return params, nil
+}
+
+// SetChatPhotoConfig allows you to set a new profile photo for a chat. Photos
+// can't be changed for private chats.
+//
+// The photo must be uploaded, a file ID or URL isn't accepted.
+type SetChatPhotoConfig struct {
+	ChatConfig
+	Photo RequestFileData // required
+}
+
+func (config SetChatPhotoConfig) method() string {
+	return "setChatPhoto"
+}
+
+func (config SetChatPhotoConfig) params() (Params, error) {
+	if config.Photo == nil || !config.Photo.NeedsUpload() {
+		return nil, errors.New("chat photo must be a new upload")
+	}
+
+	return config.ChatConfig.params()
+}
+
+func (config SetChatPhotoConfig) files() []RequestFile {
+	return []RequestFile{{
+		Name: "photo",
+		Data: config.Photo,
+	}}
+}
+
+// DeleteChatPhotoConfig allows you to delete the photo of a chat. Photos
+// can't be changed for private chats.
+type DeleteChatPhotoConfig struct {
+	ChatConfig
+}
+
+func (config DeleteChatPhotoConfig) method() string {
+	return "deleteChatPhoto"
+}
+
+// ChatInviteLinkConfig contains information about an exportChatInviteLink
+// request, which generates a new primary invite link and revokes the previous
+// one.
+type ChatInviteLinkConfig struct {
+	ChatConfig
+}
+
+func (config ChatInviteLinkConfig) method() string {
+	return "exportChatInviteLink"
+}
+
+// CreateChatInviteLinkConfig allows you to create an additional invite link
+// for a chat. The bot must be an administrator with the can_invite_users
+// right.
+//
+// MemberLimit and CreatesJoinRequest can't be used together.
+type CreateChatInviteLinkConfig struct {
+	ChatConfig
+	Name               string
+	ExpireDate         int
+	MemberLimit        int
+	CreatesJoinRequest bool
+}
+
+func (config CreateChatInviteLinkConfig) method() string {
+	return "createChatInviteLink"
+}
+
+func (config CreateChatInviteLinkConfig) params() (Params, error) {
+	params, err := config.ChatConfig.params()
+	if err != nil {
+		return params, err
+	}
+
+	err = addInviteLinkOptions(params, config.Name, config.ExpireDate, config.MemberLimit, config.CreatesJoinRequest)
+
+	return params, err
+}
+
+// EditChatInviteLinkConfig allows you to edit a non-primary invite link
+// created by the bot.
+//
+// MemberLimit and CreatesJoinRequest can't be used together.
+type EditChatInviteLinkConfig struct {
+	ChatConfig
+	InviteLink         string // required
+	Name               string
+	ExpireDate         int
+	MemberLimit        int
+	CreatesJoinRequest bool
+}
+
+func (config EditChatInviteLinkConfig) method() string {
+	return "editChatInviteLink"
+}
+
+func (config EditChatInviteLinkConfig) params() (Params, error) {
+	params, err := config.ChatConfig.params()
+	if err != nil {
+		return params, err
+	}
+
+	params["invite_link"] = config.InviteLink
+	err = addInviteLinkOptions(params, config.Name, config.ExpireDate, config.MemberLimit, config.CreatesJoinRequest)
+
+	return params, err
+}
+
+// addInviteLinkOptions adds the options shared by creating and editing an
+// invite link.
+func addInviteLinkOptions(params Params, name string, expireDate, memberLimit int, createsJoinRequest bool) error {
+	if n := utf8.RuneCountInString(name); n > 32 {
+		return fmt.Errorf("invite link name must be at most 32 characters, got %d", n)
+	}
+	if memberLimit < 0 || memberLimit > 99999 {
+		return fmt.Errorf("invite link member limit must be 1-99999, got %d", memberLimit)
+	}
+	if memberLimit != 0 && createsJoinRequest {
+		return errors.New("invite link can't have a member limit and create join requests")
+	}
+
+	params.AddNonEmpty("name", name)
+	params.AddNonZero("expire_date", expireDate)
+	params.AddNonZero("member_limit", memberLimit)
+	params.AddBool("creates_join_request", createsJoinRequest)
+
+	return nil
+}
+
+// RevokeChatInviteLinkConfig allows you to revoke an invite link created by
+// the bot. Revoking the primary link generates a new one.
+type RevokeChatInviteLinkConfig struct {
+	ChatConfig
+	InviteLink string // required
+}
+
+func (config RevokeChatInviteLinkConfig) method() string {
+	return "revokeChatInviteLink"
+}
+
+func (config RevokeChatInviteLinkConfig) params() (Params, error) {
+	params, err := config.ChatConfig.params()
+	if err != nil {
+		return params, err
+	}
+
+	params["invite_link"] = config.InviteLink
+
+	return params, nil
+}
+
+// CreateForumTopicConfig allows you to create a topic in a forum supergroup.
+// The bot must be an administrator with the can_manage_topics right.
+//
+// IconColor must be one of 0x6FB9F0, 0xFFD67E, 0xCB86DB, 0x8EEE98,
+// 0xFF93B2 or 0xFB6F5F, leave it zero for the default.
+type CreateForumTopicConfig struct {
+	ChatConfig
+	Name              string // required
+	IconColor         int
+	IconCustomEmojiID string
+}
+
+func (config CreateForumTopicConfig) method() string {
+	return "createForumTopic"
+}
+
+func (config CreateForumTopicConfig) params() (Params, error) {
+	if n := utf8.RuneCountInString(config.Name); n == 0 || n > 128 {
+		return nil, fmt.Errorf("forum topic name must be 1-128 characters, got %d", n)
+	}
+
+	params, err := config.ChatConfig.params()
+	if err != nil {
+		return params, err
+	}
+
+	params["name"] = config.Name
+	params.AddNonZero("icon_color", config.IconColor)
+	params.AddNonEmpty("icon_custom_emoji_id", config.IconCustomEmojiID)
+
+	return params, nil
+}
+
+// BaseForumTopic is a base type for configs that act on a single topic in a
+// forum supergroup.
+type BaseForumTopic struct {
+	ChatConfig
+	MessageThreadID int // required
+}
+
+func (config BaseForumTopic) params() (Params, error) {
+	params, err := config.ChatConfig.params()
+	if err != nil {
+		return params, err
+	}
+
+	params.AddNonZero("message_thread_id", config.MessageThreadID)
+
+	return params, nil
+}
+
+// EditForumTopicConfig allows you to edit the name and icon of a topic in a
+// forum supergroup.
+//
+// An empty Name keeps the current name and a nil IconCustomEmojiID keeps the
+// current icon, set it to an empty string to remove the icon.
+type EditForumTopicConfig struct {
+	BaseForumTopic
+	Name              string
+	IconCustomEmojiID *string
+}
+
+func (config EditForumTopicConfig) method() string {
+	return "editForumTopic"
+}
+
+func (config EditForumTopicConfig) params() (Params, error) {
+	if n := utf8.RuneCountInString(config.Name); n > 128 {
+		return nil, fmt.Errorf("forum topic name must be at most 128 characters, got %d", n)
+	}
+
+	params, err := config.BaseForumTopic.params()
+	if err != nil {
+		return params, err
+	}
+
+	params.AddNonEmpty("name", config.Name)
+	if config.IconCustomEmojiID != nil {
+		params["icon_custom_emoji_id"] = *config.IconCustomEmojiID
+	}
+
+	return params, nil
+}
+
+// CloseForumTopicConfig allows you to close an open topic in a forum
+// supergroup.
+type CloseForumTopicConfig struct {
+	BaseForumTopic
+}
+
+func (config CloseForumTopicConfig) method() string {
+	return "closeForumTopic"
+}
+
+// ReopenForumTopicConfig allows you to reopen a closed topic in a forum
+// supergroup.
+type ReopenForumTopicConfig struct {
+	BaseForumTopic
+}
+
+func (config ReopenForumTopicConfig) method() string {
+	return "reopenForumTopic"
+}
+
+// DeleteForumTopicConfig allows you to delete a topic in a forum supergroup
+// along with all its messages.
+type DeleteForumTopicConfig struct {
+	BaseForumTopic
+}
+
+func (config DeleteForumTopicConfig) method() string {
+	return "deleteForumTopic"
+}
+
+// EditGeneralForumTopicConfig allows you to rename the General topic in a forum
+// supergroup.
+type EditGeneralForumTopicConfig struct {
+	ChatConfig
+	Name string // required
+}
+
+func (config EditGeneralForumTopicConfig) method() string {
+	return "editGeneralForumTopic"
+}
+
+func (config EditGeneralForumTopicConfig) params() (Params, error) {
+	params, err := config.ChatConfig.params()
+	if err != nil {
+		return params, err
+	}
+
+	params["name"] = config.Name
+
+	return params, nil
+}
+
+// CloseGeneralForumTopicConfig allows you to close the General topic in a forum
+// supergroup.
+type CloseGeneralForumTopicConfig struct {
+	ChatConfig
+}
+
+func (config CloseGeneralForumTopicConfig) method() string {
+	return "closeGeneralForumTopic"
+}
+
+// ReopenGeneralForumTopicConfig allows you to reopen the closed General topic in a
+// forum supergroup.
+type ReopenGeneralForumTopicConfig struct {
+	ChatConfig
+}
+
+func (config ReopenGeneralForumTopicConfig) method() string {
+	return "reopenGeneralForumTopic"
+}
+
+// HideGeneralForumTopicConfig allows you to hide the General topic in a forum
+// supergroup. The topic is closed if it was open.
+type HideGeneralForumTopicConfig struct {
+	ChatConfig
+}
+
+func (config HideGeneralForumTopicConfig) method() string {
+	return "hideGeneralForumTopic"
+}
+
+// UnhideGeneralForumTopicConfig allows you to unhide the General topic in a forum
+// supergroup.
+type UnhideGeneralForumTopicConfig struct {
+	ChatConfig
+}
+
+func (config UnhideGeneralForumTopicConfig) method() string {
+	return "unhideGeneralForumTopic"
+}
+
+// LogOutConfig is a request to log out from the cloud Bot API server.
+//
+// Call it before running the bot against a local Bot API server. After a
+// successful call the bot can't log in to the cloud server again for 10
+// minutes.
+type LogOutConfig struct{}
+
+func (LogOutConfig) method() string {
+	return "logOut"
+}
+
+func (LogOutConfig) params() (Params, error) {
+	return nil, nil
+}
+
+// CloseConfig is a request to close the bot instance on a local Bot API server
+// before moving it to another server.
+//
+// The method will return error 429 in the first 10 minutes after the bot is
+// launched.
+type CloseConfig struct{}
+
+func (CloseConfig) method() string {
+	return "close"
+}
+
+func (CloseConfig) params() (Params, error) {
+	return nil, nil
+}
+
+// CallbackConfig contains information on making a CallbackQuery response.
+type CallbackConfig struct {
+	CallbackQueryID string `json:"callback_query_id"`
+	Text            string `json:"text"`
+	ShowAlert       bool   `json:"show_alert"`
+	URL             string `json:"url"`
+	CacheTime       int    `json:"cache_time"`
+}
+
+func (config CallbackConfig) method() string {
+	return "answerCallbackQuery"
+}
+
+func (config CallbackConfig) params() (Params, error) {
+	params := make(Params)
+
+	params["callback_query_id"] = config.CallbackQueryID
+	params.AddNonEmpty("text", config.Text)
+	params.AddBool("show_alert", config.ShowAlert)
+	params.AddNonEmpty("url", config.URL)
+	params.AddNonZero("cache_time", config.CacheTime)
+
+	return params, nil
+}
+
+// PhotoConfig contains information about a SendPhoto request.
+type PhotoConfig struct {
+	BaseFile
+	Caption         string
+	ParseMode       string
+	CaptionEntities []MessageEntity
+}
+
+func (config PhotoConfig) params() (Params, error) {
+	params, err := config.BaseFile.params()
+	if err != nil {
+		return params, err
+	}
+
+	params.AddNonEmpty("caption", config.Caption)
+	params.AddNonEmpty("parse_mode", config.ParseMode)
+	err = params.AddInterface("caption_entities", config.CaptionEntities)
+
+	return params, err
+}
+
+func (config PhotoConfig) method() string {
+	return "sendPhoto"
+}
+
+func (config PhotoConfig) files() []RequestFile {
+	return []RequestFile{{
+		Name: "photo",
+		Data: config.File,
+	}}
+}
+
+// RawRequest calls any Bot API method with the given fields, for methods
+// that don't have a config type yet.
+//
+// Files are uploaded when any of them need it, like with other configs.
+type RawRequest struct {
+	Method string
+	Fields Params
+	Files  []RequestFile
+}
+
+func (config RawRequest) method() string {
+	return config.Method
+}
+
+func (config RawRequest) params() (Params, error) {
+	params := make(Params, len(config.Fields))
+
+	for key, value := range config.Fields {
+		params[key] = value
+	}
+
+	return params, nil
+}
+
+func (config RawRequest) files() []RequestFile {
+	return config.Files
+}
+
+// DocumentConfig contains information about a SendDocument request.
+type DocumentConfig struct {
+	BaseFile
+	Thumb                       RequestFileData
+	Caption                     string
+	ParseMode                   string
+	CaptionEntities             []MessageEntity
+	DisableContentTypeDetection bool
+}
+
+func (config DocumentConfig) params() (Params, error) {
+	params, err := config.BaseFile.params()
+	if err != nil {
+		return params, err
+	}
+
+	params.AddNonEmpty("caption", config.Caption)
+	params.AddNonEmpty("parse_mode", config.ParseMode)
+	params.AddBool("disable_content_type_detection", config.DisableContentTypeDetection)
+	err = params.AddInterface("caption_entities", config.CaptionEntities)
+
+	return params, err
+}
+
+func (config DocumentConfig) method() string {
+	return "sendDocument"
+}
+
+func (config DocumentConfig) files() []RequestFile {
+	files := []RequestFile{{
+		Name: "document",
+		Data: config.File,
+	}}
+
+	if config.Thumb != nil {
+		files = append(files, RequestFile{
+			Name: "thumb",
+			Data: config.Thumb,
+		})
+	}
+
+	return files
+}
+
+// ForwardConfig contains information about a ForwardMessage request.
+//
+// Forwarded messages can't have a reply markup, so setting ReplyMarkup is an
+// error.
+type ForwardConfig struct {
+	BaseChat
+	FromChatID          int64 // required
+	FromChannelUsername string
+	MessageID           int // required
+}
+
+func (config ForwardConfig) params() (Params, error) {
+	if config.ReplyMarkup != nil {
+		return nil, errors.New("reply markup can't be set on a forwarded message, use CopyMessageConfig instead")
+	}
+
+	params, err := config.BaseChat.params()
+	if err != nil {
+		return params, err
+	}
+
+	err = params.AddFirstValid("from_chat_id", config.FromChatID, config.FromChannelUsername)
+	params.AddNonZero("message_id", config.MessageID)
+
+	return params, err
+}
+
+func (config ForwardConfig) method() string {
+	return "forwardMessage"
+}
+
+// CopyMessageConfig contains information about a copyMessage request.
+type CopyMessageConfig struct {
+	BaseChat
+	FromChatID          int64 // required
+	FromChannelUsername string
+	MessageID           int // required
+	Caption             string
+	ParseMode           string
+	CaptionEntities     []MessageEntity
+}
+
+func (config CopyMessageConfig) params() (Params, error) {
+	params, err := config.BaseChat.params()
+	if err != nil {
+		return params, err
+	}
+
+	err = params.AddFirstValid("from_chat_id", config.FromChatID, config.FromChannelUsername)
+	if err != nil {
+		return params, err
+	}
+	params.AddNonZero("message_id", config.MessageID)
+	params.AddNonEmpty("caption", config.Caption)
+	params.AddNonEmpty("parse_mode", config.ParseMode)
+	err = params.AddInterface("caption_entities", config.CaptionEntities)
+
+	return params, err
+}
+
+func (config CopyMessageConfig) method() string {
+	return "copyMessage"
+}
+
+// AudioConfig contains information about a SendAudio request.
+type AudioConfig struct {
+	BaseFile
+	Thumb           RequestFileData
+	Caption         string
+	ParseMode       string
+	CaptionEntities []MessageEntity
+	Duration        int
+	Performer       string
+	Title           string
+}
+
+func (config AudioConfig) params() (Params, error) {
+	params, err := config.BaseFile.params()
+	if err != nil {
+		return params, err
+	}
+
+	params.AddNonZero("duration", config.Duration)
+	params.AddNonEmpty("performer", config.Performer)
+	params.AddNonEmpty("title", config.Title)
+	params.AddNonEmpty("caption", config.Caption)
+	params.AddNonEmpty("parse_mode", config.ParseMode)
+	err = params.AddInterface("caption_entities", config.CaptionEntities)
+
+	return params, err
+}
+
+func (config AudioConfig) method() string {
+	return "sendAudio"
+}
+
+func (config AudioConfig) files() []RequestFile {
+	files := []RequestFile{{
+		Name: "audio",
+		Data: config.File,
+	}}
+
+	if config.Thumb != nil {
+		files = append(files, RequestFile{
+			Name: "thumb",
+			Data: config.Thumb,
+		})
+	}
+
+	return files
+}
+
+// VideoConfig contains information about a SendVideo request.
+type VideoConfig struct {
+	BaseFile
+	Thumb             RequestFileData
+	Duration          int
+	Width             int
+	Height            int
+	Caption           string
+	ParseMode         string
+	CaptionEntities   []MessageEntity
+	SupportsStreaming bool
+}
+
+func (config VideoConfig) params() (Params, error) {
+	params, err := config.BaseFile.params()
+	if err != nil {
+		return params, err
+	}
+
+	params.AddNonZero("duration", config.Duration)
+	params.AddNonZero("width", config.Width)
+	params.AddNonZero("height", config.Height)
+	params.AddNonEmpty("caption", config.Caption)
+	params.AddNonEmpty("parse_mode", config.ParseMode)
+	params.AddBool("supports_streaming", config.SupportsStreaming)
+	err = params.AddInterface("caption_entities", config.CaptionEntities)
+
+	return params, err
+}
+
+func (config VideoConfig) method() string {
+	return "sendVideo"
+}
+
+func (config VideoConfig) files() []RequestFile {
+	files := []RequestFile{{
+		Name: "video",
+		Data: config.File,
+	}}
+
+	if config.Thumb != nil {
+		files = append(files, RequestFile{
+			Name: "thumb",
+			Data: config.Thumb,
+		})
+	}
+
+	return files
+}
+
+// VoiceConfig contains information about a SendVoice request.
+type VoiceConfig struct {
+	BaseFile
+	Caption         string
+	ParseMode       string
+	CaptionEntities []MessageEntity
+	Duration        int
+}
+
+func (config VoiceConfig) params() (Params, error) {
+	params, err := config.BaseFile.params()
+	if err != nil {
+		return params, err
+	}
+
+	params.AddNonZero("duration", config.Duration)
+	params.AddNonEmpty("caption", config.Caption)
+	params.AddNonEmpty("parse_mode", config.ParseMode)
+	err = params.AddInterface("caption_entities", config.CaptionEntities)
+
+	return params, err
+}
+
+func (config VoiceConfig) method() string {
+	return "sendVoice"
+}
+
+func (config VoiceConfig) files() []RequestFile {
+	return []RequestFile{{
+		Name: "voice",
+		Data: config.File,
+	}}
+}
+
+// AnimationConfig contains information about a SendAnimation request.
+type AnimationConfig struct {
+	BaseFile
+	Thumb           RequestFileData
+	Duration        int
+	Width           int
+	Height          int
+	Caption         string
+	ParseMode       string
+	CaptionEntities []MessageEntity
+}
+
+func (config AnimationConfig) params() (Params, error) {
+	params, err := config.BaseFile.params()
+	if err != nil {
+		return params, err
+	}
+
+	params.AddNonZero("duration", config.Duration)
+	params.AddNonZero("width", config.Width)
+	params.AddNonZero("height", config.Height)
+	params.AddNonEmpty("caption", config.Caption)
+	params.AddNonEmpty("parse_mode", config.ParseMode)
+	err = params.AddInterface("caption_entities", config.CaptionEntities)
+
+	return params, err
+}
+
+func (config AnimationConfig) method() string {
+	return "sendAnimation"
+}
+
+func (config AnimationConfig) files() []RequestFile {
+	files := []RequestFile{{
+		Name: "animation",
+		Data: config.File,
+	}}
+
+	if config.Thumb != nil {
+		files = append(files, RequestFile{
+			Name: "thumb",
+			Data: config.Thumb,
+		})
+	}
+
+	return files
+}
+
+// StickerConfig contains information about a SendSticker request.
+type StickerConfig struct {
+	BaseFile
+}
+
+func (config StickerConfig) params() (Params, error) {
+	return config.BaseFile.params()
+}
+
+func (config StickerConfig) method() string {
+	return "sendSticker"
+}
+
+func (config StickerConfig) files() []RequestFile {
+	return []RequestFile{{
+		Name: "sticker",
+		Data: config.File,
+	}}
+}
+
+// VideoNoteConfig contains information about a SendVideoNote request.
+//
+// Video notes can't have a caption.
+type VideoNoteConfig struct {
+	BaseFile
+	Thumb    RequestFileData
+	Duration int
+	Length   int
+}
+
+func (config VideoNoteConfig) params() (Params, error) {
+	params, err := config.BaseFile.params()
+	if err != nil {
+		return params, err
+	}
+
+	params.AddNonZero("duration", config.Duration)
+	params.AddNonZero("length", config.Length)
+
+	return params, nil
+}
+
+func (config VideoNoteConfig) method() string {
+	return "sendVideoNote"
+}
+
+func (config VideoNoteConfig) files() []RequestFile {
+	files := []RequestFile{{
+		Name: "video_note",
+		Data: config.File,
+	}}
+
+	if config.Thumb != nil {
+		files = append(files, RequestFile{
+			Name: "thumb",
+			Data: config.Thumb,
+		})
+	}
+
+	return files
+}
+
+// prepareInputMediaForParams replaces every file that needs to be uploaded
+// with an attach:// reference to the multipart field it is uploaded as.
+func prepareInputMediaForParams(inputMedia []interface{}) []interface{} {
+	newMedia := make([]interface{}, len(inputMedia))
+
+	for idx, media := range inputMedia {
+		newMedia[idx] = prepareInputMediaParam(dereferenceInputMedia(media), idx)
+	}
+
+	return newMedia
+}
+
+// prepareInputMediaForFiles returns the files of the media that need to be
+// uploaded, named to match prepareInputMediaForParams.
+func prepareInputMediaForFiles(inputMedia []interface{}) []RequestFile {
+	var files []RequestFile
+
+	for idx, media := range inputMedia {
+		files = append(files, prepareInputMediaFile(dereferenceInputMedia(media), idx)...)
+	}
+
+	return files
+}
+
+func dereferenceInputMedia(media interface{}) interface{} {
+	switch m := media.(type) {
+	case *InputMediaPhoto:
+		return *m
+	case *InputMediaVideo:
+		return *m
+	case *InputMediaAudio:
+		return *m
+	case *InputMediaDocument:
+		return *m
+	default:
+		return media
+	}
+}
+
+func attachName(idx int, suffix string) string {
+	return fmt.Sprintf("file-%d%s", idx, suffix)
+}
+
+func attach(data RequestFileData, idx int, suffix string) RequestFileData {
+	if data != nil && data.NeedsUpload() {
+		return fileAttach("attach://" + attachName(idx, suffix))
+	}
+
+	return data
+}
+
+func prepareInputMediaParam(inputMedia interface{}, idx int) interface{} {
+	switch m := inputMedia.(type) {
+	case InputMediaPhoto:
+		m.Media = attach(m.Media, idx, "")
+		return m
+	case InputMediaVideo:
+		m.Media = attach(m.Media, idx, "")
+		m.Thumb = attach(m.Thumb, idx, "-thumb")
+		return m
+	case InputMediaAudio:
+		m.Media = attach(m.Media, idx, "")
+		m.Thumb = attach(m.Thumb, idx, "-thumb")
+		return m
+	case InputMediaDocument:
+		m.Media = attach(m.Media, idx, "")
+		m.Thumb = attach(m.Thumb, idx, "-thumb")
+		return m
+	}
+
+	return nil
+}
+
+func prepareInputMediaFile(inputMedia interface{}, idx int) []RequestFile {
+	var media, thumb RequestFileData
+
+	switch m := inputMedia.(type) {
+	case InputMediaPhoto:
+		media = m.Media
+	case InputMediaVideo:
+		media, thumb = m.Media, m.Thumb
+	case InputMediaAudio:
+		media, thumb = m.Media, m.Thumb
+	case InputMediaDocument:
+		media, thumb = m.Media, m.Thumb
+	}
+
+	var files []RequestFile
+
+	if media != nil && media.NeedsUpload() {
+		files = append(files, RequestFile{Name: attachName(idx, ""), Data: media})
+	}
+	if thumb != nil && thumb.NeedsUpload() {
+		files = append(files, RequestFile{Name: attachName(idx, "-thumb"), Data: thumb})
+	}
+
+	return files
+}
+
+// Constant values for ChatActions
+const (
+	ChatTyping          = "typing"
+	ChatUploadPhoto     = "upload_photo"
+	ChatRecordVideo     = "record_video"
+	ChatUploadVideo     = "upload_video"
+	ChatRecordVoice     = "record_voice"
+	ChatUploadVoice     = "upload_voice"
+	ChatUploadDocument  = "upload_document"
+	ChatChooseSticker   = "choose_sticker"
+	ChatFindLocation    = "find_location"
+	ChatRecordVideoNote = "record_video_note"
+	ChatUploadVideoNote = "upload_video_note"
+)
+
+// ChatActionConfig contains information about a SendChatAction request.
+type ChatActionConfig struct {
+	BaseChat
+	Action string // required
+}
+
+func (config ChatActionConfig) params() (Params, error) {
+	params, err := config.BaseChat.params()
+	if err != nil {
+		return params, err
+	}
+
+	params["action"] = config.Action
+
+	return params, nil
+}
+
+func (config ChatActionConfig) method() string {
+	return "sendChatAction"
+}
+
+// SetMessageReactionConfig allows you to change the bot's reactions on a
+// message. An empty Reaction removes them.
+type SetMessageReactionConfig struct {
+	ChatConfig
+	MessageID int // required
+	Reaction  []ReactionType
+	IsBig     bool
+}
+
+func (config SetMessageReactionConfig) method() string {
+	return "setMessageReaction"
+}
+
+func (config SetMessageReactionConfig) params() (Params, error) {
+	params, err := config.ChatConfig.params()
+	if err != nil {
+		return params, err
+	}
+
+	params.AddNonZero("message_id", config.MessageID)
+	reaction := config.Reaction
+	if reaction == nil {
+		reaction = []ReactionType{}
+	}
+	if err := params.AddInterface("reaction", reaction); err != nil {
+		return params, err
+	}
+	params.AddBool("is_big", config.IsBig)
+
+	return params, nil
+}
+
+// DiceConfig contains information about a sendDice request.
+type DiceConfig struct {
+	BaseChat
+	// Emoji on which the dice throw animation is based.
+	// Currently, must be one of 🎲, 🎯, 🏀, ⚽, 🎳, or 🎰.
+	// Dice can have values 1-6 for 🎲, 🎯, and 🎳, values 1-5 for 🏀
+	// and ⚽, and values 1-64 for 🎰.
+	// Defaults to “🎲”
+	Emoji string
+}
+
+func (config DiceConfig) method() string {
+	return "sendDice"
+}
+
+func (config DiceConfig) params() (Params, error) {
+	params, err := config.BaseChat.params()
+	if err != nil {
+		return params, err
+	}
+
+	params.AddNonEmpty("emoji", config.Emoji)
+
+	return params, err
+}
+
+// SendPollConfig allows you to send a poll.
+//
+// Leave IsAnonymous nil to use Telegram's default, an anonymous poll.
+type SendPollConfig struct {
+	BaseChat
+	Question              string
+	Options               []string
+	IsAnonymous           *bool
+	Type                  string
+	AllowsMultipleAnswers bool
+	CorrectOptionID       int64
+	Explanation           string
+	ExplanationParseMode  string
+	ExplanationEntities   []MessageEntity
+	OpenPeriod            int
+	CloseDate             int
+	IsClosed              bool
+}
+
+func (config SendPollConfig) params() (Params, error) {
+	params, err := config.BaseChat.params()
+	if err != nil {
+		return params, err
+	}
+
+	params["question"] = config.Question
+	if err = params.AddInterface("options", config.Options); err != nil {
+		return params, err
+	}
+	params.AddBoolPtr("is_anonymous", config.IsAnonymous)
+	params.AddNonEmpty("type", config.Type)
+	params.AddBool("allows_multiple_answers", config.AllowsMultipleAnswers)
+	if config.Type == "quiz" {
+		params["correct_option_id"] = strconv.FormatInt(config.CorrectOptionID, 10)
+	}
+	params.AddBool("is_closed", config.IsClosed)
+	params.AddNonEmpty("explanation", config.Explanation)
+	params.AddNonEmpty("explanation_parse_mode", config.ExplanationParseMode)
+	params.AddNonZero("open_period", config.OpenPeriod)
+	params.AddNonZero("close_date", config.CloseDate)
+	err = params.AddInterface("explanation_entities", config.ExplanationEntities)
+
+	return params, err
+}
+
+func (SendPollConfig) method() string {
+	return "sendPoll"
+}
@@ -0,0 +1,420 @@
+package tgapimanager
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMediaGroupConfigValidate(t *testing.T) {
+	photo := NewInputMediaPhoto(FileID("photo"))
+	video := NewInputMediaVideo(FileID("video"))
+	document := NewInputMediaDocument(FileID("document"))
+
+	tests := []struct {
+		name  string
+		media []interface{}
+		err   string
+	}{
+		{"photo and document", []interface{}{photo, document}, "documents must be grouped separately"},
+		{"photo and video", []interface{}{photo, video}, ""},
+		{"single item", []interface{}{photo}, "2-10 items"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := MediaGroupConfig{ChatID: 1, Media: test.media}
+
+			_, err := config.params()
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("expected error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestIndependentChatPermissions(t *testing.T) {
+	independent := true
+	permissions := &ChatPermissions{CanSendPhotos: true}
+
+	configs := []Chattable{
+		RestrictChatMemberConfig{
+			ChatMemberConfig:           ChatMemberConfig{ChatID: 1, UserID: 2},
+			Permissions:                permissions,
+			IndependentChatPermissions: &independent,
+		},
+		SetChatPermissionsConfig{
+			ChatConfig:                 ChatConfig{ChatID: 1},
+			Permissions:                permissions,
+			IndependentChatPermissions: &independent,
+		},
+	}
+
+	for _, config := range configs {
+		params, err := config.params()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if params["use_independent_chat_permissions"] != "true" {
+			t.Errorf("%s: expected independent permissions, got %q", config.method(), params["use_independent_chat_permissions"])
+		}
+		if params["permissions"] != `{"can_send_photos":true}` {
+			t.Errorf("%s: unexpected permissions %s", config.method(), params["permissions"])
+		}
+	}
+
+	params, _ := SetChatPermissionsConfig{ChatConfig: ChatConfig{ChatID: 1}}.params()
+	if _, ok := params["use_independent_chat_permissions"]; ok {
+		t.Error("expected unset independent permissions to be omitted")
+	}
+}
+
+func TestForwardAndCopyFlags(t *testing.T) {
+	base := BaseChat{ChatID: 1, DisableNotification: true, ProtectContent: true}
+
+	params, err := CopyMessageConfig{BaseChat: base, FromChatID: 2, MessageID: 3}.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["disable_notification"] != "true" || params["protect_content"] != "true" {
+		t.Errorf("expected flags on copy, got %v", params)
+	}
+
+	params, err = ForwardConfig{BaseChat: base, FromChatID: 2, MessageID: 3}.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["disable_notification"] != "true" || params["protect_content"] != "true" {
+		t.Errorf("expected flags on forward, got %v", params)
+	}
+
+	base.ReplyMarkup = NewInlineKeyboardMarkup(NewInlineKeyboardRow(NewInlineKeyboardButtonData("a", "b")))
+
+	if _, err := (CopyMessageConfig{BaseChat: base, FromChatID: 2, MessageID: 3}).params(); err != nil {
+		t.Errorf("expected markup to be allowed on copy, got %v", err)
+	}
+	if _, err := (ForwardConfig{BaseChat: base, FromChatID: 2, MessageID: 3}).params(); err == nil {
+		t.Error("expected markup on a forward to fail")
+	}
+}
+
+func TestBaseChatMessageThreadID(t *testing.T) {
+	config := NewMessage(-100, "hello")
+
+	params, err := config.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := params["message_thread_id"]; ok {
+		t.Error("expected no message_thread_id outside a topic")
+	}
+
+	config.MessageThreadID = 42
+	params, err = config.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["message_thread_id"] != "42" {
+		t.Errorf("expected message_thread_id 42, got %q", params["message_thread_id"])
+	}
+
+	var message Message
+	if err := json.Unmarshal([]byte(`{"message_id":1,"message_thread_id":42,"date":0,"chat":{"id":-100}}`), &message); err != nil {
+		t.Fatal(err)
+	}
+	if message.MessageThreadID != 42 {
+		t.Errorf("expected message thread 42, got %d", message.MessageThreadID)
+	}
+}
+
+func TestLinkPreviewOptions(t *testing.T) {
+	message := NewMessage(1, "https://example.com")
+	message.DisableWebPagePreview = true
+
+	params, err := message.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["disable_web_page_preview"] != "true" {
+		t.Errorf("expected the legacy flag without options, got %v", params)
+	}
+
+	options := &LinkPreviewOptions{URL: "https://example.com/preview", PreferLargeMedia: true}
+	message.LinkPreviewOptions = options
+	edit := NewEditMessageText(1, 2, "https://example.com")
+	edit.LinkPreviewOptions = options
+
+	for _, config := range []Chattable{message, edit} {
+		params, err := config.params()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := params["link_preview_options"]; got != `{"url":"https://example.com/preview","prefer_large_media":true}` {
+			t.Errorf("%s: unexpected link_preview_options %s", config.method(), got)
+		}
+		if _, ok := params["disable_web_page_preview"]; ok {
+			t.Errorf("%s: expected the legacy flag to be replaced by the options", config.method())
+		}
+	}
+}
+
+func TestBaseChatRawReplyMarkup(t *testing.T) {
+	keyboard := `{"inline_keyboard": [[{"text": "Open", "url": "https://example.com"}]]}`
+
+	config := NewMessage(1, "hello")
+	config.ReplyMarkup = json.RawMessage(keyboard)
+
+	params, err := config.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := params["reply_markup"]; got != keyboard {
+		t.Errorf("expected reply_markup %s, got %s", keyboard, got)
+	}
+
+	config.ReplyMarkup = json.RawMessage(`{"inline_keyboard": [`)
+	if _, err := config.params(); err == nil {
+		t.Error("expected invalid JSON to be rejected")
+	}
+}
+
+func TestSetChatTitleConfigLength(t *testing.T) {
+	tests := []struct {
+		title string
+		valid bool
+	}{
+		{"", false},
+		{"Example", true},
+		{strings.Repeat("é", 255), true},
+		{strings.Repeat("a", 256), false},
+	}
+
+	for _, test := range tests {
+		_, err := NewSetChatTitle(1, test.title).params()
+		if (err == nil) != test.valid {
+			t.Errorf("title of %d characters: expected valid %t, got error %v", len([]rune(test.title)), test.valid, err)
+		}
+	}
+}
+
+func TestCreateChatInviteLinkConfigParams(t *testing.T) {
+	config := CreateChatInviteLinkConfig{
+		ChatConfig:  ChatConfig{ChatID: -100},
+		Name:        "Trial",
+		ExpireDate:  1700000000,
+		MemberLimit: 10,
+	}
+
+	params, err := config.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Params{"chat_id": "-100", "name": "Trial", "expire_date": "1700000000", "member_limit": "10"}
+	if len(params) != len(expected) {
+		t.Errorf("expected params %v, got %v", expected, params)
+	}
+	for key, value := range expected {
+		if params[key] != value {
+			t.Errorf("expected %s=%s, got %s", key, value, params[key])
+		}
+	}
+
+	config.CreatesJoinRequest = true
+	if _, err := config.params(); err == nil {
+		t.Error("expected an error for a member limit with join requests")
+	}
+
+	config.CreatesJoinRequest = false
+	config.MemberLimit = 100000
+	if _, err := config.params(); err == nil {
+		t.Error("expected an error for a member limit over 99999")
+	}
+}
+
+func TestSetMessageReactionConfigParams(t *testing.T) {
+	config := NewSetMessageReaction(1, 2, "👍")
+	config.IsBig = true
+
+	params, err := config.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["reaction"] != `[{"type":"emoji","emoji":"👍"}]` {
+		t.Errorf("unexpected reaction %s", params["reaction"])
+	}
+	if params["message_id"] != "2" || params["is_big"] != "true" {
+		t.Errorf("unexpected params %v", params)
+	}
+
+	params, err = SetMessageReactionConfig{ChatConfig: ChatConfig{ChatID: 1}, MessageID: 2}.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["reaction"] != "[]" {
+		t.Errorf("expected an empty reaction list to remove reactions, got %s", params["reaction"])
+	}
+}
+
+func TestGetGameHighScoresConfigAddressing(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   GetGameHighScoresConfig
+		expected map[string]string
+	}{
+		{
+			"chat message",
+			GetGameHighScoresConfig{UserID: 7, ChatID: 1, MessageID: 2},
+			map[string]string{"user_id": "7", "chat_id": "1", "message_id": "2"},
+		},
+		{
+			"inline message",
+			GetGameHighScoresConfig{UserID: 7, InlineMessageID: "inline"},
+			map[string]string{"user_id": "7", "inline_message_id": "inline"},
+		},
+		{
+			"both",
+			GetGameHighScoresConfig{UserID: 7, ChatID: 1, MessageID: 2, InlineMessageID: "inline"},
+			nil,
+		},
+		{
+			"neither",
+			GetGameHighScoresConfig{UserID: 7},
+			nil,
+		},
+		{
+			"inline message with chat",
+			GetGameHighScoresConfig{UserID: 7, ChatID: 1, InlineMessageID: "inline"},
+			nil,
+		},
+		{
+			"inline message with channel",
+			GetGameHighScoresConfig{UserID: 7, ChannelUsername: "@channel", InlineMessageID: "inline"},
+			nil,
+		},
+		{
+			"inline message with message ID",
+			GetGameHighScoresConfig{UserID: 7, MessageID: 2, InlineMessageID: "inline"},
+			nil,
+		},
+		{
+			"chat without message ID",
+			GetGameHighScoresConfig{UserID: 7, ChatID: 1},
+			nil,
+		},
+		{
+			"message ID without chat",
+			GetGameHighScoresConfig{UserID: 7, MessageID: 2},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params, err := test.config.params()
+			if test.expected == nil {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(params) != len(test.expected) {
+				t.Errorf("expected params %v, got %v", test.expected, params)
+			}
+			for key, value := range test.expected {
+				if params[key] != value {
+					t.Errorf("expected %s to be %s, got %s", key, value, params[key])
+				}
+			}
+		})
+	}
+}
+
+func TestLocationConfigParams(t *testing.T) {
+	config := NewLocation(1, 0, 30.5)
+
+	params, err := config.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["latitude"] != "0.000000" || params["longitude"] != "30.500000" {
+		t.Errorf("expected coordinates 0, 30.5, got %s, %s", params["latitude"], params["longitude"])
+	}
+	for _, key := range []string{"horizontal_accuracy", "live_period", "heading", "proximity_alert_radius"} {
+		if _, ok := params[key]; ok {
+			t.Errorf("expected zero %s to be omitted", key)
+		}
+	}
+
+	config.ProximityAlertRadius = 100
+	if _, err := config.params(); err == nil {
+		t.Error("expected a proximity alert without a live period to fail")
+	}
+
+	config.LivePeriod = 60
+	if _, err := config.params(); err != nil {
+		t.Errorf("expected a proximity alert on a live location to be valid, got %v", err)
+	}
+}
+
+func TestEditMessageLiveLocationConfigParams(t *testing.T) {
+	config := EditMessageLiveLocationConfig{
+		BaseEdit:  BaseEdit{ChatID: 1, MessageID: 2},
+		Longitude: 30.5,
+	}
+
+	params, err := config.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["latitude"] != "0.000000" || params["longitude"] != "30.500000" {
+		t.Errorf("expected coordinates 0, 30.5, got %s, %s", params["latitude"], params["longitude"])
+	}
+}
+
+func TestVenueConfigParams(t *testing.T) {
+	config := NewVenue(1, "Null Island", "Gulf of Guinea", 0, 0)
+
+	params, err := config.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["latitude"] != "0.000000" || params["longitude"] != "0.000000" {
+		t.Errorf("expected coordinates 0, 0, got %s, %s", params["latitude"], params["longitude"])
+	}
+}
+
+func TestAnswerInlineQueryConfigResults(t *testing.T) {
+	config := AnswerInlineQueryConfig{
+		InlineQueryID: "query",
+		Results: []interface{}{
+			NewInlineQueryResultArticle("1", "Hello", "Hello, world"),
+			NewInlineQueryResultPhotoWithThumb("2", "https://example.com/photo.jpg", "https://example.com/thumb.jpg"),
+		},
+		IsPersonal: true,
+	}
+
+	params, err := config.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[{"type":"article","id":"1","title":"Hello","input_message_content":{"message_text":"Hello, world"}},` +
+		`{"type":"photo","id":"2","photo_url":"https://example.com/photo.jpg","thumbnail_url":"https://example.com/thumb.jpg"}]`
+	if params["results"] != expected {
+		t.Errorf("expected results %s, got %s", expected, params["results"])
+	}
+	if params["inline_query_id"] != "query" || params["is_personal"] != "true" {
+		t.Errorf("unexpected params %v", params)
+	}
+}
@@ -2,6 +2,8 @@ package tgapimanager
 
 import (
 	"net/url"
+	"strings"
+	"unicode/utf16"
 )
 
 // NewMessage creates a new Message.
@@ -18,6 +20,166 @@ func NewMessage(chatID int64, text string) MessageConfig {
 	}
 }
 
+// NewForward creates a new forward.
+//
+// chatID is where to send it, fromChatID is the source chat,
+// and messageID is the ID of the original message.
+func NewForward(chatID int64, fromChatID int64, messageID int) ForwardConfig {
+	return ForwardConfig{
+		BaseChat:   BaseChat{ChatID: chatID},
+		FromChatID: fromChatID,
+		MessageID:  messageID,
+	}
+}
+
+// NewCopyMessage creates a new copy message.
+//
+// chatID is where to send it, fromChatID is the source chat,
+// and messageID is the ID of the original message.
+func NewCopyMessage(chatID int64, fromChatID int64, messageID int) CopyMessageConfig {
+	return CopyMessageConfig{
+		BaseChat:   BaseChat{ChatID: chatID},
+		FromChatID: fromChatID,
+		MessageID:  messageID,
+	}
+}
+
+// NewPhoto creates a new sendPhoto request.
+//
+// chatID is where to send it, file is a string path to the file,
+// FileReader, or FileBytes. An existing photo can be sent again with FileID.
+func NewPhoto(chatID int64, file RequestFileData) PhotoConfig {
+	return PhotoConfig{
+		BaseFile: BaseFile{
+			BaseChat: BaseChat{ChatID: chatID},
+			File:     file,
+		},
+	}
+}
+
+// NewDocument creates a new sendDocument request.
+func NewDocument(chatID int64, file RequestFileData) DocumentConfig {
+	return DocumentConfig{
+		BaseFile: BaseFile{
+			BaseChat: BaseChat{ChatID: chatID},
+			File:     file,
+		},
+	}
+}
+
+// NewAudio creates a new sendAudio request.
+func NewAudio(chatID int64, file RequestFileData) AudioConfig {
+	return AudioConfig{
+		BaseFile: BaseFile{
+			BaseChat: BaseChat{ChatID: chatID},
+			File:     file,
+		},
+	}
+}
+
+// NewVideo creates a new sendVideo request.
+func NewVideo(chatID int64, file RequestFileData) VideoConfig {
+	return VideoConfig{
+		BaseFile: BaseFile{
+			BaseChat: BaseChat{ChatID: chatID},
+			File:     file,
+		},
+	}
+}
+
+// NewVoice creates a new sendVoice request.
+func NewVoice(chatID int64, file RequestFileData) VoiceConfig {
+	return VoiceConfig{
+		BaseFile: BaseFile{
+			BaseChat: BaseChat{ChatID: chatID},
+			File:     file,
+		},
+	}
+}
+
+// NewAnimation creates a new sendAnimation request.
+func NewAnimation(chatID int64, file RequestFileData) AnimationConfig {
+	return AnimationConfig{
+		BaseFile: BaseFile{
+			BaseChat: BaseChat{ChatID: chatID},
+			File:     file,
+		},
+	}
+}
+
+// NewSticker creates a new sendSticker request.
+func NewSticker(chatID int64, file RequestFileData) StickerConfig {
+	return StickerConfig{
+		BaseFile: BaseFile{
+			BaseChat: BaseChat{ChatID: chatID},
+			File:     file,
+		},
+	}
+}
+
+// NewVideoNote creates a new sendVideoNote request.
+//
+// chatID is where to send it, length is the diameter of the video in pixels.
+func NewVideoNote(chatID int64, length int, file RequestFileData) VideoNoteConfig {
+	return VideoNoteConfig{
+		BaseFile: BaseFile{
+			BaseChat: BaseChat{ChatID: chatID},
+			File:     file,
+		},
+		Length: length,
+	}
+}
+
+// NewMediaGroup creates a new media group. Files should be an array of
+// two to ten InputMediaPhoto or InputMediaVideo, or only InputMediaAudio, or
+// only InputMediaDocument.
+func NewMediaGroup(chatID int64, files []interface{}) MediaGroupConfig {
+	return MediaGroupConfig{
+		ChatID: chatID,
+		Media:  files,
+	}
+}
+
+// NewInputMediaPhoto creates a new InputMediaPhoto.
+func NewInputMediaPhoto(media RequestFileData) InputMediaPhoto {
+	return InputMediaPhoto{
+		BaseInputMedia{
+			Type:  "photo",
+			Media: media,
+		},
+	}
+}
+
+// NewInputMediaVideo creates a new InputMediaVideo.
+func NewInputMediaVideo(media RequestFileData) InputMediaVideo {
+	return InputMediaVideo{
+		BaseInputMedia: BaseInputMedia{
+			Type:  "video",
+			Media: media,
+		},
+	}
+}
+
+// NewInputMediaAudio creates a new InputMediaAudio.
+func NewInputMediaAudio(media RequestFileData) InputMediaAudio {
+	return InputMediaAudio{
+		BaseInputMedia: BaseInputMedia{
+			Type:  "audio",
+			Media: media,
+		},
+	}
+}
+
+// NewInputMediaDocument creates a new InputMediaDocument.
+func NewInputMediaDocument(media RequestFileData) InputMediaDocument {
+	return InputMediaDocument{
+		BaseInputMedia: BaseInputMedia{
+			Type:  "document",
+			Media: media,
+		},
+	}
+}
+
 // NewLocation shares your location.
 //
 // chatID is where to send it, latitude and longitude are coordinates.
@@ -44,6 +206,180 @@ func NewVenue(chatID int64, title, address string, latitude, longitude float64)
 	}
 }
 
+// NewReplyWithQuote creates a new Message replying to to and quoting the
+// first occurrence of quote in its text.
+//
+// If quote can't be found in the text, the message is a plain reply. If to
+// has no Chat, the message has no chat either and fails to send.
+func NewReplyWithQuote(to *Message, quote string, text string) MessageConfig {
+	if to.Chat == nil {
+		return NewMessage(0, text)
+	}
+
+	msg := NewMessage(to.Chat.ID, text)
+	msg.ReplyParameters = &ReplyParameters{
+		MessageID: to.MessageID,
+	}
+
+	if idx := strings.Index(to.Text, quote); quote != "" && idx >= 0 {
+		msg.ReplyParameters.Quote = quote
+		msg.ReplyParameters.QuotePosition = len(utf16.Encode([]rune(to.Text[:idx])))
+	}
+
+	return msg
+}
+
+// NewContact allows you to send a shared contact.
+func NewContact(chatID int64, phoneNumber, firstName string) ContactConfig {
+	return ContactConfig{
+		BaseChat: BaseChat{
+			ChatID: chatID,
+		},
+		PhoneNumber: phoneNumber,
+		FirstName:   firstName,
+	}
+}
+
+// NewChatAction sets a chat action.
+// Actions last for 5 seconds, or until your next action.
+//
+// chatID is where to send it, action should be set via Chat constants.
+func NewChatAction(chatID int64, action string) ChatActionConfig {
+	return ChatActionConfig{
+		BaseChat: BaseChat{ChatID: chatID},
+		Action:   action,
+	}
+}
+
+// NewSetMessageReaction creates a request to react to a message with a
+// single emoji.
+func NewSetMessageReaction(chatID int64, messageID int, emoji string) SetMessageReactionConfig {
+	return SetMessageReactionConfig{
+		ChatConfig: ChatConfig{ChatID: chatID},
+		MessageID:  messageID,
+		Reaction:   []ReactionType{{Type: "emoji", Emoji: emoji}},
+	}
+}
+
+// NewDice allows you to send a random dice roll.
+func NewDice(chatID int64) DiceConfig {
+	return DiceConfig{
+		BaseChat: BaseChat{
+			ChatID: chatID,
+		},
+	}
+}
+
+// NewDiceWithEmoji allows you to send a random roll of one of many types.
+//
+// Emoji may be 🎲 (1-6), 🎯 (1-6), 🏀 (1-5), ⚽ (1-5), 🎳 (1-6), or 🎰 (1-64).
+func NewDiceWithEmoji(chatID int64, emoji string) DiceConfig {
+	return DiceConfig{
+		BaseChat: BaseChat{
+			ChatID: chatID,
+		},
+		Emoji: emoji,
+	}
+}
+
+// NewPoll allows you to create a new poll.
+func NewPoll(chatID int64, question string, options ...string) SendPollConfig {
+	return SendPollConfig{
+		BaseChat: BaseChat{
+			ChatID: chatID,
+		},
+		Question: question,
+		Options:  options,
+	}
+}
+
+// NewQuiz allows you to create a new quiz, where correct is the index of the
+// correct option.
+func NewQuiz(chatID int64, question string, correct int, options ...string) SendPollConfig {
+	config := NewPoll(chatID, question, options...)
+	config.Type = "quiz"
+	config.CorrectOptionID = int64(correct)
+
+	return config
+}
+
+// NewBanChatMember creates a request to ban userID from chatID until they
+// are unbanned.
+func NewBanChatMember(chatID, userID int64) BanChatMemberConfig {
+	return BanChatMemberConfig{
+		ChatMemberConfig: ChatMemberConfig{
+			ChatID: chatID,
+			UserID: userID,
+		},
+	}
+}
+
+// NewUnbanChatMember creates a request to unban userID from chatID.
+func NewUnbanChatMember(chatID, userID int64) UnbanChatMemberConfig {
+	return UnbanChatMemberConfig{
+		ChatMemberConfig: ChatMemberConfig{
+			ChatID: chatID,
+			UserID: userID,
+		},
+	}
+}
+
+// NewSetChatTitle creates a request to change the title of chatID.
+func NewSetChatTitle(chatID int64, title string) SetChatTitleConfig {
+	return SetChatTitleConfig{
+		ChatConfig: ChatConfig{ChatID: chatID},
+		Title:      title,
+	}
+}
+
+// NewSetChatPhoto creates a request to upload photo as the photo of chatID.
+func NewSetChatPhoto(chatID int64, photo RequestFileData) SetChatPhotoConfig {
+	return SetChatPhotoConfig{
+		ChatConfig: ChatConfig{ChatID: chatID},
+		Photo:      photo,
+	}
+}
+
+// NewSetChatDescription creates a request to change the description of
+// chatID.
+func NewSetChatDescription(chatID int64, description string) SetChatDescriptionConfig {
+	return SetChatDescriptionConfig{
+		ChatConfig:  ChatConfig{ChatID: chatID},
+		Description: description,
+	}
+}
+
+// NewInlineQueryResultArticle creates a new inline query article.
+func NewInlineQueryResultArticle(id, title, messageText string) InlineQueryResultArticle {
+	return InlineQueryResultArticle{
+		Type:  "article",
+		ID:    id,
+		Title: title,
+		InputMessageContent: InputTextMessageContent{
+			Text: messageText,
+		},
+	}
+}
+
+// NewInlineQueryResultPhoto creates a new inline query photo.
+func NewInlineQueryResultPhoto(id, url string) InlineQueryResultPhoto {
+	return InlineQueryResultPhoto{
+		Type: "photo",
+		ID:   id,
+		URL:  url,
+	}
+}
+
+// NewInlineQueryResultPhotoWithThumb creates a new inline query photo.
+func NewInlineQueryResultPhotoWithThumb(id, url, thumb string) InlineQueryResultPhoto {
+	return InlineQueryResultPhoto{
+		Type:     "photo",
+		ID:       id,
+		URL:      url,
+		ThumbURL: thumb,
+	}
+}
+
 // NewUpdate gets updates since the last Offset.
 //
 // offset is the last Update ID to include.
@@ -168,6 +504,15 @@ func NewKeyboardButtonLocation(text string) KeyboardButton {
 	}
 }
 
+// NewKeyboardButtonWebApp creates a keyboard button that opens the Web App
+// at url upon click.
+func NewKeyboardButtonWebApp(text, url string) KeyboardButton {
+	return KeyboardButton{
+		Text:   text,
+		WebApp: &WebAppInfo{URL: url},
+	}
+}
+
 // NewKeyboardButtonRow creates a row of keyboard buttons.
 func NewKeyboardButtonRow(buttons ...KeyboardButton) []KeyboardButton {
 	var row []KeyboardButton
@@ -223,6 +568,15 @@ func NewInlineKeyboardButtonURL(text, url string) InlineKeyboardButton {
 	}
 }
 
+// NewInlineKeyboardButtonWebApp creates an inline keyboard button with text
+// which opens the Web App at url.
+func NewInlineKeyboardButtonWebApp(text, url string) InlineKeyboardButton {
+	return InlineKeyboardButton{
+		Text:   text,
+		WebApp: &WebAppInfo{URL: url},
+	}
+}
+
 // NewInlineKeyboardButtonSwitch creates an inline keyboard button with
 // text which allows the user to switch to a chat or return to a chat.
 func NewInlineKeyboardButtonSwitch(text, sw string) InlineKeyboardButton {
@@ -252,6 +606,53 @@ func NewInlineKeyboardMarkup(rows ...[]InlineKeyboardButton) InlineKeyboardMarku
 	}
 }
 
+// NewInlineKeyboardOrdered creates a new inline keyboard of callback buttons,
+// keeping entries in the given order and placing cols buttons on each row.
+//
+// The data of each entry must be 1-64 bytes long; sending a keyboard with
+// other data fails.
+func NewInlineKeyboardOrdered(cols int, entries []KeyVal) InlineKeyboardMarkup {
+	if cols < 1 {
+		cols = 1
+	}
+
+	var keyboard [][]InlineKeyboardButton
+
+	for start := 0; start < len(entries); start += cols {
+		end := min(start+cols, len(entries))
+
+		var row []InlineKeyboardButton
+		for _, entry := range entries[start:end] {
+			row = append(row, NewInlineKeyboardButtonData(entry.Label, entry.Data))
+		}
+
+		keyboard = append(keyboard, row)
+	}
+
+	return InlineKeyboardMarkup{
+		InlineKeyboard: keyboard,
+	}
+}
+
+// NewCallback creates a new callback message.
+func NewCallback(id, text string) CallbackConfig {
+	return CallbackConfig{
+		CallbackQueryID: id,
+		Text:            text,
+		ShowAlert:       false,
+	}
+}
+
+// NewCallbackWithAlert creates a new callback message that alerts
+// the user.
+func NewCallbackWithAlert(id, text string) CallbackConfig {
+	return CallbackConfig{
+		CallbackQueryID: id,
+		Text:            text,
+		ShowAlert:       true,
+	}
+}
+
 // NewBotCommandScopeDefault represents the default scope of bot commands.
 func NewBotCommandScopeDefault() BotCommandScope {
 	return BotCommandScope{Type: "default"}
@@ -347,3 +748,122 @@ func NewDeleteMyCommandsWithScope(scope BotCommandScope) DeleteMyCommandsConfig
 func NewDeleteMyCommandsWithScopeAndLanguage(scope BotCommandScope, languageCode string) DeleteMyCommandsConfig {
 	return DeleteMyCommandsConfig{Scope: &scope, LanguageCode: languageCode}
 }
+
+// EscapeText escapes the characters of text that are reserved in parseMode,
+// so dynamic text can be included in a formatted message as is.
+//
+// parseMode is ModeMarkdown, ModeMarkdownV2 or ModeHTML; for any other mode,
+// including none, text is returned unchanged. Only escape the dynamic parts of
+// a message, formatting in text is escaped too.
+func EscapeText(parseMode string, text string) string {
+	var replacer *strings.Replacer
+
+	switch parseMode {
+	case ModeHTML:
+		replacer = strings.NewReplacer("<", "&lt;", ">", "&gt;", "&", "&amp;")
+	case ModeMarkdown:
+		replacer = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+	case ModeMarkdownV2:
+		replacer = strings.NewReplacer(
+			"\\", "\\\\",
+			"_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]", "(",
+			"\\(", ")", "\\)", "~", "\\~", "`", "\\`", ">", "\\>",
+			"#", "\\#", "+", "\\+", "-", "\\-", "=", "\\=", "|",
+			"\\|", "{", "\\{", "}", "\\}", ".", "\\.", "!", "\\!",
+		)
+	default:
+		return text
+	}
+
+	return replacer.Replace(text)
+}
+
+// textChunk is a piece of a message text along with its entities.
+type textChunk struct {
+	text     string
+	entities []MessageEntity
+}
+
+// splitMessageText splits text into chunks of at most limit UTF-16 code units.
+//
+// Entity offsets are measured in UTF-16 code units, so all the work is done on
+// the UTF-16 representation of the text.
+func splitMessageText(text string, entities []MessageEntity, limit int) []textChunk {
+	units := utf16.Encode([]rune(text))
+	if len(units) <= limit {
+		return []textChunk{{text: text, entities: entities}}
+	}
+
+	var chunks []textChunk
+
+	for start := 0; start < len(units); {
+		end := start + limit
+		if end >= len(units) {
+			end = len(units)
+		} else {
+			end = splitPoint(units, entities, start, end)
+		}
+
+		chunk := textChunk{text: string(utf16.Decode(units[start:end]))}
+
+		for _, entity := range entities {
+			entityStart := max(entity.Offset, start)
+			entityEnd := min(entity.Offset+entity.Length, end)
+			if entityStart >= entityEnd {
+				continue
+			}
+
+			entity.Offset = entityStart - start
+			entity.Length = entityEnd - entityStart
+			chunk.entities = append(chunk.entities, entity)
+		}
+
+		chunks = append(chunks, chunk)
+		start = end
+	}
+
+	return chunks
+}
+
+// splitPoint finds where to end a chunk starting at start, which may be no
+// later than end.
+//
+// It prefers a line break, then a space, and then moves back before any entity
+// that would be cut in two. It never splits a surrogate pair.
+func splitPoint(units []uint16, entities []MessageEntity, start, end int) int {
+	point := end
+
+	if i := lastIndexUnit(units[start:end], '\n'); i > 0 {
+		point = start + i + 1
+	} else if i := lastIndexUnit(units[start:end], ' '); i > 0 {
+		point = start + i + 1
+	}
+
+	for moved := true; moved; {
+		moved = false
+
+		for _, entity := range entities {
+			if entity.Offset > start && entity.Offset < point && point < entity.Offset+entity.Length {
+				point = entity.Offset
+				moved = true
+			}
+		}
+	}
+
+	// Don't leave the high half of a surrogate pair at the end of a chunk.
+	if unit := units[point-1]; unit >= 0xd800 && unit < 0xdc00 {
+		point--
+	}
+
+	return point
+}
+
+func lastIndexUnit(units []uint16, unit uint16) int {
+	for i := len(units) - 1; i >= 0; i-- {
+		if units[i] == unit {
+			return i
+		}
+	}
+
+	return -1
+}
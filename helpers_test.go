@@ -0,0 +1,133 @@
+package tgapimanager
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewInlineKeyboardOrdered(t *testing.T) {
+	entries := []KeyVal{
+		{"One", "1"}, {"Two", "2"}, {"Three", "3"}, {"Four", "4"}, {"Five", "5"},
+	}
+
+	markup := NewInlineKeyboardOrdered(2, entries)
+
+	rows := markup.InlineKeyboard
+	if len(rows) != 3 || len(rows[0]) != 2 || len(rows[1]) != 2 || len(rows[2]) != 1 {
+		t.Fatalf("expected rows of 2, 2, 1, got %v", rows)
+	}
+
+	i := 0
+	for _, row := range rows {
+		for _, button := range row {
+			if button.Text != entries[i].Label || *button.CallbackData != entries[i].Data {
+				t.Errorf("button %d: expected %v, got %s/%s", i, entries[i], button.Text, *button.CallbackData)
+			}
+			i++
+		}
+	}
+}
+
+func TestNewInlineKeyboardOrderedLongData(t *testing.T) {
+	markup := NewInlineKeyboardOrdered(1, []KeyVal{{"Long", strings.Repeat("a", 65)}})
+
+	msg := NewMessage(1, "text")
+	msg.ReplyMarkup = markup
+	if _, err := msg.params(); err == nil {
+		t.Fatal("expected callback data over 64 bytes to fail")
+	}
+}
+
+func TestNewReplyWithQuote(t *testing.T) {
+	original := &Message{MessageID: 3, Chat: &Chat{ID: 1}, Text: "👍 hello world"}
+
+	msg := NewReplyWithQuote(original, "world", "indeed")
+
+	reply := msg.ReplyParameters
+	if reply.MessageID != 3 || reply.Quote != "world" {
+		t.Fatalf("unexpected reply parameters %+v", reply)
+	}
+	if reply.QuotePosition != 9 {
+		t.Fatalf("expected quote position 9, got %d", reply.QuotePosition)
+	}
+
+	params, err := msg.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"message_id":3,"quote":"world","quote_position":9}`; params["reply_parameters"] != expected {
+		t.Fatalf("expected %s, got %s", expected, params["reply_parameters"])
+	}
+}
+
+func TestNewReplyWithQuoteWithoutChat(t *testing.T) {
+	msg := NewReplyWithQuote(&Message{MessageID: 3, Text: "hello"}, "hello", "indeed")
+
+	if _, err := msg.params(); err == nil {
+		t.Fatal("expected a reply to a message without a chat to fail")
+	}
+}
+
+func TestNewQuiz(t *testing.T) {
+	config := NewQuiz(1, "2 + 2?", 1, "3", "4", "5")
+
+	params, err := config.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"type":              "quiz",
+		"correct_option_id": "1",
+		"options":           `["3","4","5"]`,
+	}
+	for key, value := range expected {
+		if params[key] != value {
+			t.Errorf("expected %s to be %s, got %s", key, value, params[key])
+		}
+	}
+	if _, ok := params["is_anonymous"]; ok {
+		t.Error("expected is_anonymous to be left to Telegram's default")
+	}
+}
+
+func TestNewWebAppButtons(t *testing.T) {
+	keyboard := NewReplyKeyboard(NewKeyboardButtonRow(NewKeyboardButtonWebApp("Open", "https://example.com/app")))
+	inline := NewInlineKeyboardMarkup(NewInlineKeyboardRow(NewInlineKeyboardButtonWebApp("Open", "https://example.com/app")))
+
+	for _, markup := range []interface{}{keyboard, inline} {
+		data, err := json.Marshal(markup)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), `"web_app":{"url":"https://example.com/app"}`) {
+			t.Errorf("expected web_app in %s", data)
+		}
+	}
+
+	if err := inline.Validate(); err != nil {
+		t.Errorf("expected valid inline keyboard, got %v", err)
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		parseMode string
+		text      string
+		expected  string
+	}{
+		{ModeMarkdownV2, "a_b*c", `a\_b\*c`},
+		{ModeMarkdownV2, `1.5 (approx) \ done!`, `1\.5 \(approx\) \\ done\!`},
+		{ModeMarkdown, "a_b*c [d]", `a\_b\*c \[d]`},
+		{ModeHTML, "<b>Tom & Jerry</b>", "&lt;b&gt;Tom &amp; Jerry&lt;/b&gt;"},
+		{"", "a_b <c>", "a_b <c>"},
+		{"unknown", "text", "text"},
+	}
+
+	for _, test := range tests {
+		if got := EscapeText(test.parseMode, test.text); got != test.expected {
+			t.Errorf("%s %q: expected %q, got %q", test.parseMode, test.text, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package tgapimanager
+
+import (
+	"container/list"
+	"sync"
+)
+
+// fileIDCacheSize is how many file IDs the bot remembers for uploaded
+// content before evicting the least recently used.
+const fileIDCacheSize = 1024
+
+// lastMessagesSize is how many chats UpsertMessage remembers a message for
+// before forgetting the least recently used.
+const lastMessagesSize = 1024
+
+// lruCache is a bounded cache that evicts the least recently used entry once
+// it is full. It is safe for concurrent use.
+type lruCache[K comparable, V any] struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[K]*list.Element
+}
+
+type lruCacheEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+func newLRUCache[K comparable, V any](size int) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[K]*list.Element),
+	}
+}
+
+// get returns the value cached for key and marks it as recently used.
+func (c *lruCache[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(element)
+
+	return element.Value.(*lruCacheEntry[K, V]).value, true
+}
+
+// add caches value for key, evicting the least recently used entry if the
+// cache is full.
+func (c *lruCache[K, V]) add(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*lruCacheEntry[K, V]).value = value
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruCacheEntry[K, V]{key: key, value: value})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruCacheEntry[K, V]).key)
+	}
+}
+
+// remove forgets the value cached for key.
+func (c *lruCache[K, V]) remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+}
@@ -0,0 +1,26 @@
+package tgapimanager
+
+import "testing"
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newLRUCache[string, string](2)
+
+	cache.add("a", "file-a")
+	cache.add("b", "file-b")
+	cache.get("a")
+	cache.add("c", "file-c")
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for hash, expected := range map[string]string{"a": "file-a", "c": "file-c"} {
+		if fileID, ok := cache.get(hash); !ok || fileID != expected {
+			t.Errorf("expected %s to be cached as %s, got %q", hash, expected, fileID)
+		}
+	}
+
+	cache.remove("a")
+	if _, ok := cache.get("a"); ok {
+		t.Error("expected a to be removed")
+	}
+}
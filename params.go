@@ -2,11 +2,16 @@ package tgapimanager
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"strconv"
 )
 
 // Params represents a set of parameters that gets passed to a request.
+//
+// Each key holds a single value. The Bot API expects arrays and objects, such
+// as allowed_updates, as a single JSON encoded value, which AddInterface
+// produces, rather than as repeated form fields.
 type Params map[string]string
 
 // AddNonEmpty adds a value if it not an empty string.
@@ -37,6 +42,14 @@ func (p Params) AddBool(key string, value bool) {
 	}
 }
 
+// AddBoolPtr adds a value of a bool if it is set, so an unset value can be
+// told apart from false.
+func (p Params) AddBoolPtr(key string, value *bool) {
+	if value != nil {
+		p[key] = strconv.FormatBool(*value)
+	}
+}
+
 // AddNonZeroFloat adds a floating point value that is not zero.
 func (p Params) AddNonZeroFloat(key string, value float64) {
 	if value != 0 {
@@ -45,11 +58,26 @@ func (p Params) AddNonZeroFloat(key string, value float64) {
 }
 
 // AddInterface adds an interface if it is not nil and can be JSON marshalled.
+//
+// A json.RawMessage is taken to be already encoded and is added verbatim.
 func (p Params) AddInterface(key string, value interface{}) error {
 	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
 		return nil
 	}
 
+	if raw, ok := value.(json.RawMessage); ok {
+		if len(raw) == 0 {
+			return nil
+		}
+		if !json.Valid(raw) {
+			return fmt.Errorf("%s is not valid JSON", key)
+		}
+
+		p[key] = string(raw)
+
+		return nil
+	}
+
 	b, err := json.Marshal(value)
 	if err != nil {
 		return err
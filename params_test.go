@@ -0,0 +1,82 @@
+package tgapimanager
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParamsAddBoolPtr(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name     string
+		value    *bool
+		expected string
+		set      bool
+	}{
+		{"nil", nil, "", false},
+		{"true", &yes, "true", true},
+		{"false", &no, "false", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params := make(Params)
+			params.AddBoolPtr("key", test.value)
+
+			value, ok := params["key"]
+			if ok != test.set || value != test.expected {
+				t.Errorf("expected %q (set %t), got %q (set %t)", test.expected, test.set, value, ok)
+			}
+		})
+	}
+}
+
+func TestAllowedUpdatesRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		encoded string
+	}{
+		{"restricted", []string{UpdateTypeMessage, UpdateTypeCallbackQuery}, `["message","callback_query"]`},
+		{"default", []string{}, `[]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := NewUpdate(0)
+			config.AllowedUpdates = test.allowed
+
+			params, err := config.params()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			form, err := url.ParseQuery(buildParams(params).Encode())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if values := form["allowed_updates"]; len(values) != 1 || values[0] != test.encoded {
+				t.Fatalf("expected a single value %s, got %v", test.encoded, values)
+			}
+
+			var decoded []string
+			if err := json.Unmarshal([]byte(form.Get("allowed_updates")), &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decoded, test.allowed) {
+				t.Errorf("expected %v, got %v", test.allowed, decoded)
+			}
+		})
+	}
+
+	params, err := NewUpdate(0).params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := params["allowed_updates"]; ok {
+		t.Error("expected nil AllowedUpdates to be omitted")
+	}
+}
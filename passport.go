@@ -69,6 +69,19 @@ type (
 		// "identity_card" and "internal_passport". The file can be decrypted
 		// and verified using the accompanying EncryptedCredentials.
 		Selfie *PassportFile `json:"selfie,omitempty"`
+
+		// Array of encrypted files with translated versions of documents
+		// provided by the user. Available if requested for "passport",
+		// "driver_license", "identity_card", "internal_passport",
+		// "utility_bill", "bank_statement", "rental_agreement",
+		// "passport_registration" and "temporary_registration" types. Files
+		// can be decrypted and verified using the accompanying
+		// EncryptedCredentials.
+		Translation []PassportFile `json:"translation,omitempty"`
+
+		// Base64-encoded element hash for using in
+		// PassportElementErrorUnspecified
+		Hash string `json:"hash"`
 	}
 
 	// EncryptedCredentials contains data required for decrypting and
@@ -0,0 +1,115 @@
+package tgapimanager
+
+import (
+	"sync"
+	"time"
+)
+
+// ThrottledEditor keeps the text of a message up to date without editing it
+// more than once per interval, such as for a progress message that changes
+// faster than the chat's rate limit allows.
+//
+// Updates made while an edit is pending are coalesced, so only the latest text
+// is sent. Close must be called once done to make sure the final text is
+// shown.
+type ThrottledEditor struct {
+	bot       *BotAPI
+	chatID    int64
+	messageID int
+	interval  time.Duration
+
+	// editMu serializes edits so they are made in order.
+	editMu sync.Mutex
+
+	mu     sync.Mutex
+	text   string
+	sent   string
+	last   time.Time
+	timer  *time.Timer
+	closed bool
+	err    error
+}
+
+// NewThrottledEditor creates a ThrottledEditor for the message with messageID
+// in chatID, which edits it at most once per interval.
+func NewThrottledEditor(bot *BotAPI, chatID int64, messageID int, interval time.Duration) *ThrottledEditor {
+	return &ThrottledEditor{
+		bot:       bot,
+		chatID:    chatID,
+		messageID: messageID,
+		interval:  interval,
+	}
+}
+
+// Update sets the text the message should show. The edit happens in the
+// background once the interval since the previous edit has passed.
+//
+// Updates after Close are ignored.
+func (e *ThrottledEditor) Update(text string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return
+	}
+
+	e.text = text
+	if e.timer != nil {
+		// The pending edit will pick up the new text.
+		return
+	}
+
+	wait := e.interval - time.Since(e.last)
+	if wait < 0 {
+		wait = 0
+	}
+	e.timer = time.AfterFunc(wait, e.flush)
+}
+
+// Close stops further updates and edits the message to the latest text if it
+// isn't shown yet. It returns the error from the last edit, if it failed.
+func (e *ThrottledEditor) Close() error {
+	e.mu.Lock()
+	e.closed = true
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+	e.mu.Unlock()
+
+	e.flush()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.err
+}
+
+// flush edits the message to the latest text if it changed since the last
+// edit.
+func (e *ThrottledEditor) flush() {
+	e.editMu.Lock()
+	defer e.editMu.Unlock()
+
+	e.mu.Lock()
+	text := e.text
+	e.timer = nil
+	if text == e.sent {
+		e.mu.Unlock()
+		return
+	}
+	// Set before editing so updates made during the edit wait a full
+	// interval.
+	e.last = time.Now()
+	e.mu.Unlock()
+
+	_, err := e.bot.EditOrIgnore(NewEditMessageText(e.chatID, e.messageID, text))
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.err = err
+	if err != nil {
+		return
+	}
+	e.sent = text
+}
@@ -0,0 +1,92 @@
+package tgapimanager
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestThrottledEditorCoalescesUpdates(t *testing.T) {
+	var mu sync.Mutex
+	var edits []string
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		edits = append(edits, r.FormValue("text"))
+		mu.Unlock()
+		fmt.Fprint(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":5}}}`)
+	})
+
+	editor := NewThrottledEditor(bot, 5, 1, 50*time.Millisecond)
+	for i := 1; i <= 100; i++ {
+		editor.Update(strconv.Itoa(i) + "%")
+	}
+	if err := editor.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	editor.Update("ignored")
+	time.Sleep(60 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(edits) == 0 || len(edits) > 3 {
+		t.Fatalf("expected at most 3 edits, got %d: %v", len(edits), edits)
+	}
+	if last := edits[len(edits)-1]; last != "100%" {
+		t.Errorf("expected final edit 100%%, got %s", last)
+	}
+}
+
+func TestThrottledEditorReturnsEditError(t *testing.T) {
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":false,"error_code":400,"description":"Bad Request: message to edit not found"}`)
+	})
+
+	editor := NewThrottledEditor(bot, 5, 1, time.Second)
+	editor.Update("done")
+	if err := editor.Close(); err == nil {
+		t.Fatal("expected the edit error")
+	}
+}
+
+func TestThrottledEditorClearsErrorAfterSuccessfulEdit(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+
+	bot := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		call := calls
+		mu.Unlock()
+
+		if call == 1 {
+			fmt.Fprint(w, `{"ok":false,"error_code":500,"description":"Internal Server Error"}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":5}}}`)
+	})
+
+	editor := NewThrottledEditor(bot, 5, 1, 10*time.Millisecond)
+	editor.Update("1%")
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		mu.Lock()
+		done := calls == 1
+		mu.Unlock()
+		if done {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the first edit")
+		}
+	}
+
+	editor.Update("100%")
+	if err := editor.Close(); err != nil {
+		t.Errorf("expected no error once an edit succeeded, got %v", err)
+	}
+}
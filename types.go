@@ -2,7 +2,11 @@ package tgapimanager
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
+	"unicode/utf16"
 )
 
 type UpdatesResponse struct {
@@ -10,9 +14,138 @@ type UpdatesResponse struct {
 	Result []Update `json:"result"`
 }
 
+// Update is an update response, from GetUpdates.
 type Update struct {
-	UpdateID int      `json:"update_id"`
-	Message  *Message `json:"message,omitempty"`
+	// UpdateID is the update's unique identifier.
+	// Update identifiers start from a certain positive number and increase
+	// sequentially.
+	UpdateID int `json:"update_id"`
+	// Message new incoming message of any kind — text, photo, sticker, etc.
+	//
+	// optional
+	Message *Message `json:"message,omitempty"`
+	// EditedMessage new version of a message that is known to the bot and was
+	// edited
+	//
+	// optional
+	EditedMessage *Message `json:"edited_message,omitempty"`
+	// ChannelPost new incoming channel post of any kind — text, photo, sticker,
+	// etc.
+	//
+	// optional
+	ChannelPost *Message `json:"channel_post,omitempty"`
+	// EditedChannelPost new version of a channel post that is known to the bot
+	// and was edited
+	//
+	// optional
+	EditedChannelPost *Message `json:"edited_channel_post,omitempty"`
+	// InlineQuery new incoming inline query
+	//
+	// optional
+	InlineQuery *InlineQuery `json:"inline_query,omitempty"`
+	// CallbackQuery new incoming callback query
+	//
+	// optional
+	CallbackQuery *CallbackQuery `json:"callback_query,omitempty"`
+	// MessageReaction a reaction to a message was changed by a user. The bot
+	// must be an administrator in the chat and must explicitly specify
+	// "message_reaction" in the list of allowed_updates to receive these
+	// updates. The update isn't received for reactions set by bots.
+	//
+	// optional
+	MessageReaction *MessageReactionUpdated `json:"message_reaction,omitempty"`
+	// MessageReactionCount reactions to a message with anonymous reactions
+	// were changed. The bot must be an administrator in the chat and must
+	// explicitly specify "message_reaction_count" in the list of
+	// allowed_updates to receive these updates.
+	//
+	// optional
+	MessageReactionCount *MessageReactionCountUpdated `json:"message_reaction_count,omitempty"`
+	// MyChatMember the bot's chat member status was updated in a chat. For
+	// private chats, this update is received only when the bot is blocked or
+	// unblocked by the user.
+	//
+	// optional
+	MyChatMember *ChatMemberUpdated `json:"my_chat_member,omitempty"`
+	// ChatMember a chat member's status was updated in a chat. The bot must
+	// be an administrator in the chat and must explicitly specify
+	// "chat_member" in the list of allowed_updates to receive these updates.
+	//
+	// optional
+	ChatMember *ChatMemberUpdated `json:"chat_member,omitempty"`
+	// ChatJoinRequest is a request to join the chat. The bot must have the
+	// can_invite_users administrator right in the chat to receive these
+	// updates.
+	//
+	// optional
+	ChatJoinRequest *ChatJoinRequest `json:"chat_join_request,omitempty"`
+	// PurchasedPaidMedia a user purchased paid media with a non-empty payload
+	// sent by the bot in a non-channel chat. The bot must explicitly specify
+	// "purchased_paid_media" in the list of allowed_updates to receive these
+	// updates.
+	//
+	// optional
+	PurchasedPaidMedia *PaidMediaPurchased `json:"purchased_paid_media,omitempty"`
+}
+
+// SentFrom returns the user who sent the update, whichever field is set. It
+// returns nil for updates without a user, such as channel posts.
+func (u *Update) SentFrom() *User {
+	switch {
+	case u.Message != nil:
+		return u.Message.From
+	case u.EditedMessage != nil:
+		return u.EditedMessage.From
+	case u.ChannelPost != nil:
+		return u.ChannelPost.From
+	case u.EditedChannelPost != nil:
+		return u.EditedChannelPost.From
+	case u.InlineQuery != nil:
+		return u.InlineQuery.From
+	case u.CallbackQuery != nil:
+		return u.CallbackQuery.From
+	case u.MessageReaction != nil:
+		return u.MessageReaction.User
+	case u.MyChatMember != nil:
+		return &u.MyChatMember.From
+	case u.ChatMember != nil:
+		return &u.ChatMember.From
+	case u.ChatJoinRequest != nil:
+		return &u.ChatJoinRequest.From
+	case u.PurchasedPaidMedia != nil:
+		return u.PurchasedPaidMedia.From
+	default:
+		return nil
+	}
+}
+
+// FromChat returns the chat the update comes from, whichever field is set.
+// It returns nil for updates without a chat, such as inline queries.
+func (u *Update) FromChat() *Chat {
+	switch {
+	case u.Message != nil:
+		return u.Message.Chat
+	case u.EditedMessage != nil:
+		return u.EditedMessage.Chat
+	case u.ChannelPost != nil:
+		return u.ChannelPost.Chat
+	case u.EditedChannelPost != nil:
+		return u.EditedChannelPost.Chat
+	case u.CallbackQuery != nil && u.CallbackQuery.Message != nil:
+		return u.CallbackQuery.Message.Chat
+	case u.MessageReaction != nil:
+		return u.MessageReaction.Chat
+	case u.MessageReactionCount != nil:
+		return u.MessageReactionCount.Chat
+	case u.MyChatMember != nil:
+		return &u.MyChatMember.Chat
+	case u.ChatMember != nil:
+		return &u.ChatMember.Chat
+	case u.ChatJoinRequest != nil:
+		return &u.ChatJoinRequest.Chat
+	default:
+		return nil
+	}
 }
 
 // User represents a Telegram user or bot.
@@ -75,8 +208,92 @@ func (u *User) String() string {
 	return name
 }
 
+// Chat represents a chat.
 type Chat struct {
+	// ID is a unique identifier for this chat
 	ID int64 `json:"id"`
+	// Type of chat, can be either "private", "group", "supergroup" or "channel"
+	Type string `json:"type"`
+	// Title for supergroups, channels and group chats
+	//
+	// optional
+	Title string `json:"title,omitempty"`
+	// UserName for private chats, supergroups and channels if available
+	//
+	// optional
+	UserName string `json:"username,omitempty"`
+	// FirstName of the other party in a private chat
+	//
+	// optional
+	FirstName string `json:"first_name,omitempty"`
+	// LastName of the other party in a private chat
+	//
+	// optional
+	LastName string `json:"last_name,omitempty"`
+	// Photo is a chat photo. Returned only in getChat.
+	//
+	// optional
+	Photo *ChatPhoto `json:"photo,omitempty"`
+	// Description for groups, supergroups and channel chats.
+	// Returned only in getChat.
+	//
+	// optional
+	Description string `json:"description,omitempty"`
+	// InviteLink is a primary invite link, for groups, supergroups and
+	// channel chats. Returned only in getChat.
+	//
+	// optional
+	InviteLink string `json:"invite_link,omitempty"`
+	// PinnedMessage is the most recent pinned message (by sending date).
+	// Returned only in getChat.
+	//
+	// optional
+	PinnedMessage *Message `json:"pinned_message,omitempty"`
+	// Permissions are default chat member permissions, for groups and
+	// supergroups. Returned only in getChat.
+	//
+	// optional
+	Permissions *ChatPermissions `json:"permissions,omitempty"`
+}
+
+// ChatPhoto represents a chat photo.
+type ChatPhoto struct {
+	// SmallFileID is a file identifier of small (160x160) chat photo.
+	// This file_id can be used only for photo download and
+	// only for as long as the photo is not changed.
+	SmallFileID string `json:"small_file_id"`
+	// SmallFileUniqueID is a unique file identifier of small (160x160) chat
+	// photo, which is supposed to be the same over time and for different bots.
+	// Can't be used to download or reuse the file.
+	SmallFileUniqueID string `json:"small_file_unique_id"`
+	// BigFileID is a file identifier of big (640x640) chat photo.
+	// This file_id can be used only for photo download and
+	// only for as long as the photo is not changed.
+	BigFileID string `json:"big_file_id"`
+	// BigFileUniqueID is a unique file identifier of big (640x640) chat photo,
+	// which is supposed to be the same over time and for different bots.
+	// Can't be used to download or reuse the file.
+	BigFileUniqueID string `json:"big_file_unique_id"`
+}
+
+// IsPrivate returns if the Chat is a private conversation.
+func (c *Chat) IsPrivate() bool {
+	return c.Type == ChatTypePrivate
+}
+
+// IsGroup returns if the Chat is a group.
+func (c *Chat) IsGroup() bool {
+	return c.Type == ChatTypeGroup
+}
+
+// IsSuperGroup returns if the Chat is a supergroup.
+func (c *Chat) IsSuperGroup() bool {
+	return c.Type == ChatTypeSuperGroup
+}
+
+// IsChannel returns if the Chat is a channel.
+func (c *Chat) IsChannel() bool {
+	return c.Type == ChatTypeChannel
 }
 
 // ResponseParameters are various errors that can be returned in APIResponse.
@@ -106,6 +323,10 @@ type APIResponse struct {
 type Error struct {
 	Code    int
 	Message string
+	// HTTPStatus is the status code of the HTTP response. It is set even when
+	// the response body couldn't be decoded, which usually means it came from
+	// a proxy or gateway rather than from the Bot API.
+	HTTPStatus int
 	ResponseParameters
 }
 
@@ -114,6 +335,109 @@ func (e Error) Error() string {
 	return e.Message
 }
 
+// IsMessageNotModified returns true if an edit failed because the new content
+// is exactly the same as the current content of the message.
+func (e Error) IsMessageNotModified() bool {
+	return strings.Contains(e.Message, "message is not modified")
+}
+
+// IsMessageNotFound returns true if an edit failed because the message no
+// longer exists, usually because it was deleted.
+func (e Error) IsMessageNotFound() bool {
+	return strings.Contains(e.Message, "message to edit not found")
+}
+
+// IsFloodError returns true if the request was rejected by flood control. Wait
+// for RetryAfterDuration before repeating it.
+func (e Error) IsFloodError() bool {
+	return e.Code == 429
+}
+
+// RetryAfterDuration returns how long to wait before repeating a request
+// rejected by flood control, or zero if Telegram didn't say.
+func (e Error) RetryAfterDuration() time.Duration {
+	return time.Duration(e.RetryAfter) * time.Second
+}
+
+// IsChatNotFound returns true if the chat doesn't exist or the bot has no
+// access to it, for example because it was removed from it.
+func (e Error) IsChatNotFound() bool {
+	return strings.Contains(e.Message, "chat not found")
+}
+
+// AsAPIError returns the *Error in err's chain, for example one wrapped with
+// Wrap, and whether there was one.
+func AsAPIError(err error) (*Error, bool) {
+	var apiErr *Error
+	ok := errors.As(err, &apiErr)
+
+	return apiErr, ok
+}
+
+// ReplyParameters describes the message to reply to, optionally quoting part
+// of it.
+type ReplyParameters struct {
+	// MessageID identifier of the message that will be replied to
+	MessageID int `json:"message_id"`
+	// ChatID if the message to be replied to is from a different chat
+	//
+	// optional
+	ChatID int64 `json:"chat_id,omitempty"`
+	// AllowSendingWithoutReply true if the message should be sent even if the
+	// specified message to be replied to is not found
+	//
+	// optional
+	AllowSendingWithoutReply bool `json:"allow_sending_without_reply,omitempty"`
+	// Quote is the exact part of the message to be replied to, 0-1024
+	// characters after entities parsing
+	//
+	// optional
+	Quote string `json:"quote,omitempty"`
+	// QuoteParseMode mode for parsing entities in the quote
+	//
+	// optional
+	QuoteParseMode string `json:"quote_parse_mode,omitempty"`
+	// QuoteEntities special entities that appear in the quote, which can be
+	// specified instead of QuoteParseMode
+	//
+	// optional
+	QuoteEntities []MessageEntity `json:"quote_entities,omitempty"`
+	// QuotePosition of the quote in the original message in UTF-16 code units
+	//
+	// optional
+	QuotePosition int `json:"quote_position,omitempty"`
+}
+
+// LinkPreviewOptions describes the options used for link preview generation.
+type LinkPreviewOptions struct {
+	// IsDisabled true if the link preview is disabled
+	//
+	// optional
+	IsDisabled bool `json:"is_disabled,omitempty"`
+	// URL to use for the link preview. If empty, then the first URL found in
+	// the message text will be used
+	//
+	// optional
+	URL string `json:"url,omitempty"`
+	// PreferSmallMedia true if the media in the link preview is supposed to
+	// be shrunk; ignored if the URL isn't explicitly specified or media size
+	// change isn't supported for the preview
+	//
+	// optional
+	PreferSmallMedia bool `json:"prefer_small_media,omitempty"`
+	// PreferLargeMedia true if the media in the link preview is supposed to
+	// be enlarged; ignored if the URL isn't explicitly specified or media
+	// size change isn't supported for the preview
+	//
+	// optional
+	PreferLargeMedia bool `json:"prefer_large_media,omitempty"`
+	// ShowAboveText true if the link preview must be shown above the message
+	// text; otherwise, the link preview will be shown below the message text
+	//
+	// optional
+	ShowAboveText bool `json:"show_above_text,omitempty"`
+}
+
 // MessageEntity represents one special entity in a text message.
 type MessageEntity struct {
 	// Type of the entity.
@@ -152,16 +476,150 @@ type MessageEntity struct {
 	Language string `json:"language,omitempty"`
 }
 
+// CallbackQuery represents an incoming callback query from a callback button in
+// an inline keyboard. If the button that originated the query was attached to
+// a message sent by the bot, the field message will be present. If the button
+// was attached to a message sent via the bot (in inline mode), the field
+// inline_message_id will be present. Exactly one of the fields data or
+// game_short_name will be present.
+type CallbackQuery struct {
+	// ID unique identifier for this query
+	ID string `json:"id"`
+	// From sender
+	From *User `json:"from"`
+	// Message with the callback button that originated the query.
+	// Note that message content and message date will not be available if the
+	// message is too old.
+	//
+	// optional
+	Message *Message `json:"message,omitempty"`
+	// InlineMessageID identifier of the message sent via the bot in inline
+	// mode, that originated the query.
+	//
+	// optional
+	InlineMessageID string `json:"inline_message_id,omitempty"`
+	// ChatInstance global identifier, uniquely corresponding to the chat to
+	// which the message with the callback button was sent. Useful for high
+	// scores in games.
+	ChatInstance string `json:"chat_instance"`
+	// Data associated with the callback button. Be aware that
+	// a bad client can send arbitrary data in this field.
+	//
+	// optional
+	Data string `json:"data,omitempty"`
+	// GameShortName short name of a Game to be returned, serves as the unique
+	// identifier for the game.
+	//
+	// optional
+	GameShortName string `json:"game_short_name,omitempty"`
+}
+
 // UpdatesChannel is the channel for getting updates.
 type UpdatesChannel <-chan Update
 
 // Clear discards all unprocessed incoming updates.
+//
+// It never blocks, and is safe to call after the channel has been closed.
 func (ch UpdatesChannel) Clear() {
-	for len(ch) != 0 {
-		<-ch
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
 	}
 }
 
+// Dice represents an animated emoji with a random value.
+type Dice struct {
+	// Emoji on which the dice throw animation is based
+	Emoji string `json:"emoji"`
+	// Value of the dice
+	Value int `json:"value"`
+}
+
+// WriteAccessAllowed represents a service message about a user allowing a bot
+// to write messages.
+type WriteAccessAllowed struct {
+	// FromRequest is true if the access was granted after the user accepted
+	// an explicit request from a Web App sent by the method requestWriteAccess
+	//
+	// optional
+	FromRequest bool `json:"from_request,omitempty"`
+	// WebAppName is the name of the Web App, if the access was granted when
+	// the Web App was launched from a link
+	//
+	// optional
+	WebAppName string `json:"web_app_name,omitempty"`
+	// FromAttachmentMenu is true if the access was granted when the bot was
+	// added to the attachment or side menu
+	//
+	// optional
+	FromAttachmentMenu bool `json:"from_attachment_menu,omitempty"`
+}
+
+// ForumTopic represents a forum topic.
+type ForumTopic struct {
+	// MessageThreadID is the unique identifier of the forum topic
+	MessageThreadID int `json:"message_thread_id"`
+	// Name is the name of the topic
+	Name string `json:"name"`
+	// IconColor is the color of the topic icon in RGB format
+	IconColor int `json:"icon_color"`
+	// IconCustomEmojiID is the unique identifier of the custom emoji
+	// shown as the topic icon
+	//
+	// optional
+	IconCustomEmojiID string `json:"icon_custom_emoji_id,omitempty"`
+}
+
+// ForumTopicCreated represents a service message about a new forum topic
+// created in the chat.
+type ForumTopicCreated struct {
+	// Name is the name of topic
+	Name string `json:"name"`
+	// IconColor is the color of the topic icon in RGB format
+	IconColor int `json:"icon_color"`
+	// IconCustomEmojiID is the unique identifier of the custom emoji
+	// shown as the topic icon
+	//
+	// optional
+	IconCustomEmojiID string `json:"icon_custom_emoji_id,omitempty"`
+}
+
+// ForumTopicEdited represents a service message about an edited forum topic.
+type ForumTopicEdited struct {
+	// Name is the new name of the topic, if it was edited
+	//
+	// optional
+	Name string `json:"name,omitempty"`
+	// IconCustomEmojiID is the new identifier of the custom emoji
+	// shown as the topic icon, if it was edited;
+	// an empty string if the icon was removed
+	//
+	// optional
+	IconCustomEmojiID *string `json:"icon_custom_emoji_id,omitempty"`
+}
+
+// ForumTopicClosed represents a service message about a forum topic
+// closed in the chat. Currently holds no information.
+type ForumTopicClosed struct{}
+
+// ForumTopicReopened represents a service message about a forum topic
+// reopened in the chat. Currently holds no information.
+type ForumTopicReopened struct{}
+
+// GeneralForumTopicHidden represents a service message about General forum topic
+// hidden in the chat. Currently holds no information.
+type GeneralForumTopicHidden struct{}
+
+// GeneralForumTopicUnhidden represents a service message about General forum topic
+// unhidden in the chat. Currently holds no information.
+type GeneralForumTopicUnhidden struct{}
+
 // PollOption contains information about one answer option in a poll.
 type PollOption struct {
 	// Text is the option text, 1-100 characters
@@ -220,6 +678,11 @@ type Poll struct {
 type Message struct {
 	// MessageID is a unique message identifier inside this chat
 	MessageID int `json:"message_id"`
+	// MessageThreadID is the unique identifier of the message thread or forum
+	// topic the message belongs to; for supergroups only
+	//
+	// optional
+	MessageThreadID int `json:"message_thread_id,omitempty"`
 	// From is a sender, empty for messages sent to channels;
 	//
 	// optional
@@ -231,10 +694,28 @@ type Message struct {
 	//
 	// optional
 	SenderChat *Chat `json:"sender_chat,omitempty"`
+	// SenderBusinessBot is the bot that actually sent the message on behalf
+	// of the business account. Available only for outgoing messages sent on
+	// behalf of the connected business account
+	//
+	// optional
+	SenderBusinessBot *User `json:"sender_business_bot,omitempty"`
 	// Date of the message was sent in Unix time
 	Date int `json:"date"`
+	// BusinessConnectionID is the unique identifier of the business
+	// connection from which the message was received. If non-empty, the
+	// message belongs to a chat of the corresponding business account
+	//
+	// optional
+	BusinessConnectionID string `json:"business_connection_id,omitempty"`
 	// Chat is the conversation the message belongs to
 	Chat *Chat `json:"chat"`
+	// ForwardOrigin for forwarded messages, information about the original
+	// message. The legacy Forward fields are filled in from it when the
+	// server doesn't send them.
+	//
+	// optional
+	ForwardOrigin *MessageOrigin `json:"forward_origin,omitempty"`
 	// ForwardFrom for forwarded messages, sender of the original message;
 	//
 	// optional
@@ -292,17 +773,60 @@ type Message struct {
 	// optional
 	Entities []MessageEntity `json:"entities,omitempty"`
 	// Animation message is an animation, information about the animation.
-	// For backward compatibility, when this field is set, the document field will also be set;
+	// For backward compatibility, when this field is set, the document field
+	// will also be set;
+	//
+	// optional
+	Animation *Animation `json:"animation,omitempty"`
+	// Audio message is an audio file, information about the file;
+	//
+	// optional
+	Audio *Audio `json:"audio,omitempty"`
+	// Document message is a general file, information about the file;
+	//
+	// optional
+	Document *Document `json:"document,omitempty"`
+	// Photo message is a photo, available sizes of the photo;
+	//
+	// optional
+	Photo []PhotoSize `json:"photo,omitempty"`
+	// Sticker message is a sticker, information about the sticker;
+	//
+	// optional
+	Sticker *Sticker `json:"sticker,omitempty"`
+	// Video message is a video, information about the video;
+	//
+	// optional
+	Video *Video `json:"video,omitempty"`
+	// VideoNote message is a video note, information about the video message;
+	//
+	// optional
+	VideoNote *VideoNote `json:"video_note,omitempty"`
+	// Voice message is a voice message, information about the file;
+	//
+	// optional
+	Voice *Voice `json:"voice,omitempty"`
+	// Caption for the animation, audio, document, photo, video or voice,
+	// 0-1024 characters;
+	//
 	// optional
 	Caption string `json:"caption,omitempty"`
 	// CaptionEntities;
 	//
 	// optional
 	CaptionEntities []MessageEntity `json:"caption_entities,omitempty"`
-	// Contact message is a shared contact, information about the contact;ame message is a game, information about the game;
+	// Contact message is a shared contact, information about the contact;
+	//
+	// optional
+	Contact *Contact `json:"contact,omitempty"`
+	// Poll is a native poll, information about the poll;
 	//
 	// optional
 	Poll *Poll `json:"poll,omitempty"`
+	// Dice is a dice with random value
+	//
+	// optional
+	Dice *Dice `json:"dice,omitempty"`
 	// Venue message is a venue, information about the venue.
 	// For backward compatibility, when this field is set, the location field
 	// will also be set;
@@ -314,106 +838,613 @@ type Message struct {
 	//
 	// optional
 	PassportData *PassportData `json:"passport_data,omitempty"`
-	// ProximityAlertTriggered is a service message. A user in the chat
-	// triggered another user's proximity alert while sharing Live Location
-	//
-	// Location message is a shared location, information about the location;
+	// WebAppData is a service message: data sent by a Web App
 	//
 	// optional
-	Location *Location `json:"location,omitempty"`
-}
-
-// Location represents a point on the map.
-type Location struct {
-	// Longitude as defined by sender
-	Longitude float64 `json:"longitude"`
-	// Latitude as defined by sender
-	Latitude float64 `json:"latitude"`
-	// HorizontalAccuracy is the radius of uncertainty for the location,
-	// measured in meters; 0-1500
+	WebAppData *WebAppData `json:"web_app_data,omitempty"`
+	// WriteAccessAllowed is a service message: the user allowed the bot to
+	// write messages after adding it to the attachment or side menu,
+	// launching a Web App from a link, or accepting an explicit request
+	// from a Web App sent by the method requestWriteAccess
 	//
 	// optional
-	HorizontalAccuracy float64 `json:"horizontal_accuracy,omitempty"`
-	// LivePeriod is time relative to the message sending date, during which the
-	// location can be updated, in seconds. For active live locations only.
+	WriteAccessAllowed *WriteAccessAllowed `json:"write_access_allowed,omitempty"`
+	// ForumTopicCreated is a service message: forum topic created
 	//
 	// optional
-	LivePeriod int `json:"live_period,omitempty"`
-	// Heading is the direction in which user is moving, in degrees; 1-360. For
-	// active live locations only.
+	ForumTopicCreated *ForumTopicCreated `json:"forum_topic_created,omitempty"`
+	// ForumTopicEdited is a service message: forum topic edited
 	//
 	// optional
-	Heading int `json:"heading,omitempty"`
-	// ProximityAlertRadius is the maximum distance for proximity alerts about
-	// approaching another chat member, in meters. For sent live locations only.
+	ForumTopicEdited *ForumTopicEdited `json:"forum_topic_edited,omitempty"`
+	// ForumTopicClosed is a service message: forum topic closed
 	//
 	// optional
-	ProximityAlertRadius int `json:"proximity_alert_radius,omitempty"`
-}
-
-// Venue represents a venue.
-type Venue struct {
-	// Location is the venue location
-	Location Location `json:"location"`
-	// Title is the name of the venue
-	Title string `json:"title"`
-	// Address of the venue
-	Address string `json:"address"`
-	// FoursquareID is the foursquare identifier of the venue
+	ForumTopicClosed *ForumTopicClosed `json:"forum_topic_closed,omitempty"`
+	// ForumTopicReopened is a service message: forum topic reopened
 	//
 	// optional
-	FoursquareID string `json:"foursquare_id,omitempty"`
-	// FoursquareType is the foursquare type of the venue
+	ForumTopicReopened *ForumTopicReopened `json:"forum_topic_reopened,omitempty"`
+	// GeneralForumTopicHidden is a service message: the 'General' forum topic hidden
 	//
 	// optional
-	FoursquareType string `json:"foursquare_type,omitempty"`
-	// GooglePlaceID is the Google Places identifier of the venue
+	GeneralForumTopicHidden *GeneralForumTopicHidden `json:"general_forum_topic_hidden,omitempty"`
+	// GeneralForumTopicUnhidden is a service message: the 'General' forum topic unhidden
 	//
 	// optional
-	GooglePlaceID string `json:"google_place_id,omitempty"`
-	// GooglePlaceType is the Google Places type of the venue
+	GeneralForumTopicUnhidden *GeneralForumTopicUnhidden `json:"general_forum_topic_unhidden,omitempty"`
+	// ProximityAlertTriggered is a service message. A user in the chat
+	// triggered another user's proximity alert while sharing Live Location
+	//
+	// Location message is a shared location, information about the location;
 	//
 	// optional
-	GooglePlaceType string `json:"google_place_type,omitempty"`
+	Location *Location `json:"location,omitempty"`
 }
 
-// ProximityAlertTriggered represents a service message sent when a user in the
-// chat triggers a proximity alert sent by another user.
-type ProximityAlertTriggered struct {
-	// Traveler is the user that triggered the alert
-	Traveler User `json:"traveler"`
-	// Watcher is the user that set the alert
-	Watcher User `json:"watcher"`
-	// Distance is the distance between the users
-	Distance int `json:"distance"`
+// MediaType is the kind of content a message contains.
+type MediaType string
+
+// Constant values for MediaType.
+const (
+	MediaNone      MediaType = ""
+	MediaText      MediaType = "text"
+	MediaAnimation MediaType = "animation"
+	MediaAudio     MediaType = "audio"
+	MediaDocument  MediaType = "document"
+	MediaPhoto     MediaType = "photo"
+	MediaSticker   MediaType = "sticker"
+	MediaVideo     MediaType = "video"
+	MediaVideoNote MediaType = "video_note"
+	MediaVoice     MediaType = "voice"
+)
+
+// MediaType returns the kind of media the message contains, MediaText for a
+// text message, or MediaNone for anything else, such as service messages.
+//
+// An animation is reported as MediaAnimation, even though Document is also
+// set for backward compatibility.
+func (m *Message) MediaType() MediaType {
+	switch {
+	case m.Animation != nil:
+		return MediaAnimation
+	case m.Audio != nil:
+		return MediaAudio
+	case m.Document != nil:
+		return MediaDocument
+	case len(m.Photo) > 0:
+		return MediaPhoto
+	case m.Sticker != nil:
+		return MediaSticker
+	case m.Video != nil:
+		return MediaVideo
+	case m.VideoNote != nil:
+		return MediaVideoNote
+	case m.Voice != nil:
+		return MediaVoice
+	case m.Text != "":
+		return MediaText
+	default:
+		return MediaNone
+	}
 }
 
-// Time converts the message timestamp into a Time.
-func (m *Message) Time() time.Time {
-	return time.Unix(int64(m.Date), 0)
+// FileID returns the file_id of the media in the message, for a photo that
+// of its largest size. It returns false if there is no media.
+func (m *Message) FileID() (string, bool) {
+	switch m.MediaType() {
+	case MediaAnimation:
+		return m.Animation.FileID, true
+	case MediaAudio:
+		return m.Audio.FileID, true
+	case MediaDocument:
+		return m.Document.FileID, true
+	case MediaPhoto:
+		return m.Photo[len(m.Photo)-1].FileID, true
+	case MediaSticker:
+		return m.Sticker.FileID, true
+	case MediaVideo:
+		return m.Video.FileID, true
+	case MediaVideoNote:
+		return m.VideoNote.FileID, true
+	case MediaVoice:
+		return m.Voice.FileID, true
+	default:
+		return "", false
+	}
 }
 
-type KeyboardButton struct {
-	// Text of the button. If none of the optional fields are used,
-	// it will be sent as a message when the button is pressed.
-	Text string `json:"text"`
-	// RequestContact if True, the user's phone number will be sent
-	// as a contact when the button is pressed.
-	// Available in private chats only.
+// MessageID represents a unique message identifier.
+type MessageID struct {
+	MessageID int `json:"message_id"`
+}
+
+// PhotoSize represents one size of a photo or a file / sticker thumbnail.
+type PhotoSize struct {
+	// FileID identifier for this file, which can be used to download or reuse
+	// the file
+	FileID string `json:"file_id"`
+	// FileUniqueID is the unique identifier for this file, which is supposed to
+	// be the same over time and for different bots. Can't be used to download
+	// or reuse the file.
+	FileUniqueID string `json:"file_unique_id"`
+	// Width as defined by sender
+	Width int `json:"width"`
+	// Height as defined by sender
+	Height int `json:"height"`
+	// FileSize file size
+	//
+	// optional
+	FileSize int `json:"file_size,omitempty"`
+}
+
+// Animation represents an animation file.
+type Animation struct {
+	// FileID identifier for this file, which can be used to download or reuse
+	// the file
+	FileID string `json:"file_id"`
+	// FileUniqueID is the unique identifier for this file, which is supposed to
+	// be the same over time and for different bots. Can't be used to download
+	// or reuse the file.
+	FileUniqueID string `json:"file_unique_id"`
+	// Width as defined by sender
+	Width int `json:"width"`
+	// Height as defined by sender
+	Height int `json:"height"`
+	// Duration of the file in seconds as defined by sender
+	Duration int `json:"duration"`
+	// Thumbnail thumbnail as defined by sender
 	//
 	// optional
-	RequestContact bool `json:"request_contact,omitempty"`
-	// RequestLocation if True, the user's current location will be sent when
-	// the button is pressed.
-	// Available in private chats only.
+	Thumbnail *PhotoSize `json:"thumbnail,omitempty"`
+	// FileName original filename as defined by sender
 	//
 	// optional
-	RequestLocation bool `json:"request_location,omitempty"`
-	// RequestPoll if True, the user will be asked to create a poll and send it
-	// to the bot when the button is pressed. Available in private chats only
+	FileName string `json:"file_name,omitempty"`
+	// MimeType of the file as defined by sender
 	//
 	// optional
-	RequestPoll *KeyboardButtonPollType `json:"request_poll,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	// FileSize file size in bytes
+	//
+	// optional
+	FileSize int64 `json:"file_size,omitempty"`
+}
+
+// Audio represents an audio file to be treated as music by the Telegram clients.
+type Audio struct {
+	// FileID identifier for this file, which can be used to download or reuse
+	// the file
+	FileID string `json:"file_id"`
+	// FileUniqueID is the unique identifier for this file, which is supposed to
+	// be the same over time and for different bots. Can't be used to download
+	// or reuse the file.
+	FileUniqueID string `json:"file_unique_id"`
+	// Duration of the file in seconds as defined by sender
+	Duration int `json:"duration"`
+	// Performer of the audio as defined by sender or by audio tags
+	//
+	// optional
+	Performer string `json:"performer,omitempty"`
+	// Title of the audio as defined by sender or by audio tags
+	//
+	// optional
+	Title string `json:"title,omitempty"`
+	// FileName original filename as defined by sender
+	//
+	// optional
+	FileName string `json:"file_name,omitempty"`
+	// MimeType of the file as defined by sender
+	//
+	// optional
+	MimeType string `json:"mime_type,omitempty"`
+	// FileSize file size in bytes
+	//
+	// optional
+	FileSize int64 `json:"file_size,omitempty"`
+	// Thumbnail thumbnail as defined by sender
+	//
+	// optional
+	Thumbnail *PhotoSize `json:"thumbnail,omitempty"`
+}
+
+// Document represents a general file.
+type Document struct {
+	// FileID identifier for this file, which can be used to download or reuse
+	// the file
+	FileID string `json:"file_id"`
+	// FileUniqueID is the unique identifier for this file, which is supposed to
+	// be the same over time and for different bots. Can't be used to download
+	// or reuse the file.
+	FileUniqueID string `json:"file_unique_id"`
+	// Thumbnail thumbnail as defined by sender
+	//
+	// optional
+	Thumbnail *PhotoSize `json:"thumbnail,omitempty"`
+	// FileName original filename as defined by sender
+	//
+	// optional
+	FileName string `json:"file_name,omitempty"`
+	// MimeType of the file as defined by sender
+	//
+	// optional
+	MimeType string `json:"mime_type,omitempty"`
+	// FileSize file size in bytes
+	//
+	// optional
+	FileSize int64 `json:"file_size,omitempty"`
+}
+
+// Video represents a video file.
+type Video struct {
+	// FileID identifier for this file, which can be used to download or reuse
+	// the file
+	FileID string `json:"file_id"`
+	// FileUniqueID is the unique identifier for this file, which is supposed to
+	// be the same over time and for different bots. Can't be used to download
+	// or reuse the file.
+	FileUniqueID string `json:"file_unique_id"`
+	// Width as defined by sender
+	Width int `json:"width"`
+	// Height as defined by sender
+	Height int `json:"height"`
+	// Duration of the file in seconds as defined by sender
+	Duration int `json:"duration"`
+	// Thumbnail thumbnail as defined by sender
+	//
+	// optional
+	Thumbnail *PhotoSize `json:"thumbnail,omitempty"`
+	// FileName original filename as defined by sender
+	//
+	// optional
+	FileName string `json:"file_name,omitempty"`
+	// MimeType of the file as defined by sender
+	//
+	// optional
+	MimeType string `json:"mime_type,omitempty"`
+	// FileSize file size in bytes
+	//
+	// optional
+	FileSize int64 `json:"file_size,omitempty"`
+}
+
+// VideoNote object represents a video message.
+type VideoNote struct {
+	// FileID identifier for this file, which can be used to download or reuse
+	// the file
+	FileID string `json:"file_id"`
+	// FileUniqueID is the unique identifier for this file, which is supposed to
+	// be the same over time and for different bots. Can't be used to download
+	// or reuse the file.
+	FileUniqueID string `json:"file_unique_id"`
+	// Length video width and height (diameter of the video message) as defined by sender
+	Length int `json:"length"`
+	// Duration of the file in seconds as defined by sender
+	Duration int `json:"duration"`
+	// Thumbnail thumbnail as defined by sender
+	//
+	// optional
+	Thumbnail *PhotoSize `json:"thumbnail,omitempty"`
+	// FileSize file size
+	//
+	// optional
+	FileSize int `json:"file_size,omitempty"`
+}
+
+// Voice represents a voice note.
+type Voice struct {
+	// FileID identifier for this file, which can be used to download or reuse
+	// the file
+	FileID string `json:"file_id"`
+	// FileUniqueID is the unique identifier for this file, which is supposed to
+	// be the same over time and for different bots. Can't be used to download
+	// or reuse the file.
+	FileUniqueID string `json:"file_unique_id"`
+	// Duration of the file in seconds as defined by sender
+	Duration int `json:"duration"`
+	// MimeType of the file as defined by sender
+	//
+	// optional
+	MimeType string `json:"mime_type,omitempty"`
+	// FileSize file size in bytes
+	//
+	// optional
+	FileSize int64 `json:"file_size,omitempty"`
+}
+
+// Sticker represents a sticker.
+type Sticker struct {
+	// FileID identifier for this file, which can be used to download or reuse
+	// the file
+	FileID string `json:"file_id"`
+	// FileUniqueID is the unique identifier for this file, which is supposed to
+	// be the same over time and for different bots. Can't be used to download
+	// or reuse the file.
+	FileUniqueID string `json:"file_unique_id"`
+	// Type of the sticker, currently one of "regular", "mask" or
+	// "custom_emoji"
+	Type string `json:"type"`
+	// Width as defined by sender
+	Width int `json:"width"`
+	// Height as defined by sender
+	Height int `json:"height"`
+	// IsAnimated true, if the sticker is animated
+	IsAnimated bool `json:"is_animated"`
+	// IsVideo true, if the sticker is a video sticker
+	IsVideo bool `json:"is_video"`
+	// Thumbnail thumbnail as defined by sender
+	//
+	// optional
+	Thumbnail *PhotoSize `json:"thumbnail,omitempty"`
+	// Emoji associated with the sticker
+	//
+	// optional
+	Emoji string `json:"emoji,omitempty"`
+	// SetName of the sticker set to which the sticker belongs
+	//
+	// optional
+	SetName string `json:"set_name,omitempty"`
+	// FileSize file size
+	//
+	// optional
+	FileSize int `json:"file_size,omitempty"`
+}
+
+// File contains information about a file to download from Telegram.
+type File struct {
+	// FileID identifier for this file, which can be used to download or reuse
+	// the file
+	FileID string `json:"file_id"`
+	// FileUniqueID is the unique identifier for this file, which is supposed to
+	// be the same over time and for different bots. Can't be used to download
+	// or reuse the file.
+	FileUniqueID string `json:"file_unique_id"`
+	// FileSize file size, if known
+	//
+	// optional
+	FileSize int64 `json:"file_size,omitempty"`
+	// FilePath file path
+	//
+	// optional
+	FilePath string `json:"file_path,omitempty"`
+}
+
+// Contact represents a phone contact.
+//
+// Note that LastName, UserID and VCard are optional.
+type Contact struct {
+	// PhoneNumber contact's phone number
+	PhoneNumber string `json:"phone_number"`
+	// FirstName contact's first name
+	FirstName string `json:"first_name"`
+	// LastName contact's last name
+	//
+	// optional
+	LastName string `json:"last_name,omitempty"`
+	// UserID contact's user identifier in Telegram
+	//
+	// optional
+	UserID int64 `json:"user_id,omitempty"`
+	// VCard is additional data about the contact in the form of a vCard.
+	//
+	// optional
+	VCard string `json:"vcard,omitempty"`
+}
+
+// Location represents a point on the map.
+type Location struct {
+	// Longitude as defined by sender
+	Longitude float64 `json:"longitude"`
+	// Latitude as defined by sender
+	Latitude float64 `json:"latitude"`
+	// HorizontalAccuracy is the radius of uncertainty for the location,
+	// measured in meters; 0-1500
+	//
+	// optional
+	HorizontalAccuracy float64 `json:"horizontal_accuracy,omitempty"`
+	// LivePeriod is time relative to the message sending date, during which the
+	// location can be updated, in seconds. For active live locations only.
+	//
+	// optional
+	LivePeriod int `json:"live_period,omitempty"`
+	// Heading is the direction in which user is moving, in degrees; 1-360. For
+	// active live locations only.
+	//
+	// optional
+	Heading int `json:"heading,omitempty"`
+	// ProximityAlertRadius is the maximum distance for proximity alerts about
+	// approaching another chat member, in meters. For sent live locations only.
+	//
+	// optional
+	ProximityAlertRadius int `json:"proximity_alert_radius,omitempty"`
+}
+
+// Venue represents a venue.
+type Venue struct {
+	// Location is the venue location
+	Location Location `json:"location"`
+	// Title is the name of the venue
+	Title string `json:"title"`
+	// Address of the venue
+	Address string `json:"address"`
+	// FoursquareID is the foursquare identifier of the venue
+	//
+	// optional
+	FoursquareID string `json:"foursquare_id,omitempty"`
+	// FoursquareType is the foursquare type of the venue
+	//
+	// optional
+	FoursquareType string `json:"foursquare_type,omitempty"`
+	// GooglePlaceID is the Google Places identifier of the venue
+	//
+	// optional
+	GooglePlaceID string `json:"google_place_id,omitempty"`
+	// GooglePlaceType is the Google Places type of the venue
+	//
+	// optional
+	GooglePlaceType string `json:"google_place_type,omitempty"`
+}
+
+// ProximityAlertTriggered represents a service message sent when a user in the
+// chat triggers a proximity alert sent by another user.
+type ProximityAlertTriggered struct {
+	// Traveler is the user that triggered the alert
+	Traveler User `json:"traveler"`
+	// Watcher is the user that set the alert
+	Watcher User `json:"watcher"`
+	// Distance is the distance between the users
+	Distance int `json:"distance"`
+}
+
+// UnmarshalJSON decodes a message, filling in the legacy Forward fields from
+// ForwardOrigin for servers that only send the latter.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type message Message
+	if err := json.Unmarshal(data, (*message)(m)); err != nil {
+		return err
+	}
+
+	origin := m.ForwardOrigin
+	if origin == nil || m.ForwardDate != 0 {
+		return nil
+	}
+
+	m.ForwardDate = origin.Date
+	switch origin.Type {
+	case MessageOriginTypeUser:
+		m.ForwardFrom = origin.SenderUser
+	case MessageOriginTypeHiddenUser:
+		m.ForwardSenderName = origin.SenderUserName
+	case MessageOriginTypeChat:
+		m.ForwardFromChat = origin.SenderChat
+		m.ForwardSignature = origin.AuthorSignature
+	case MessageOriginTypeChannel:
+		m.ForwardFromChat = origin.Chat
+		m.ForwardFromMessageID = origin.MessageID
+		m.ForwardSignature = origin.AuthorSignature
+	}
+
+	return nil
+}
+
+// Time converts the message timestamp into a Time.
+func (m *Message) Time() time.Time {
+	return time.Unix(int64(m.Date), 0)
+}
+
+// Age returns how long ago the message was sent.
+func (m *Message) Age() time.Duration {
+	return time.Since(m.Time())
+}
+
+// IsOlderThan returns if the message was sent more than d ago, for example
+// to skip messages that were queued while the bot was down.
+func (m *Message) IsOlderThan(d time.Duration) bool {
+	return m.Age() > d
+}
+
+// IsFromAnonymousAdmin returns true if the message was sent by an anonymous
+// group administrator, in which case SenderChat is the group itself and From
+// is not set.
+func (m *Message) IsFromAnonymousAdmin() bool {
+	return m.SenderChat != nil && m.Chat != nil && m.SenderChat.ID == m.Chat.ID
+}
+
+// EffectiveSender returns whoever sent the message. Exactly one of user or
+// chat is set: chat for messages sent on behalf of a chat, such as channel
+// posts and anonymous administrator messages, and user otherwise.
+func (m *Message) EffectiveSender() (user *User, chat *Chat) {
+	if m.SenderChat != nil {
+		return nil, m.SenderChat
+	}
+
+	return m.From, nil
+}
+
+// IsCommand returns true if the message starts with a bot command.
+func (m *Message) IsCommand() bool {
+	_, ok := m.commandEntity()
+	return ok
+}
+
+// Command returns the command the message starts with, without the leading
+// slash and any @botname suffix. It is empty if the message isn't a command.
+func (m *Message) Command() string {
+	command := m.commandWithAt()
+	if i := strings.Index(command, "@"); i != -1 {
+		command = command[:i]
+	}
+
+	return command
+}
+
+// CommandArguments returns the text following the command, without the
+// separating whitespace. It is empty if the message isn't a command.
+func (m *Message) CommandArguments() string {
+	entity, ok := m.commandEntity()
+	if !ok {
+		return ""
+	}
+
+	text := utf16.Encode([]rune(m.Text))
+	return strings.TrimSpace(string(utf16.Decode(text[entity.Length:])))
+}
+
+// commandWithAt returns the command including the @botname suffix, if any.
+func (m *Message) commandWithAt() string {
+	entity, ok := m.commandEntity()
+	if !ok {
+		return ""
+	}
+
+	// Entity offsets are in UTF-16 code units, so don't slice the string
+	// directly.
+	text := utf16.Encode([]rune(m.Text))
+	return string(utf16.Decode(text[1:entity.Length]))
+}
+
+// commandEntity returns the bot_command entity at the start of the message.
+func (m *Message) commandEntity() (MessageEntity, bool) {
+	if len(m.Entities) == 0 {
+		return MessageEntity{}, false
+	}
+
+	entity := m.Entities[0]
+	if entity.Type != "bot_command" || entity.Offset != 0 || entity.Length < 1 ||
+		entity.Length > len(utf16.Encode([]rune(m.Text))) {
+		return MessageEntity{}, false
+	}
+
+	return entity, true
+}
+
+type KeyboardButton struct {
+	// Text of the button. If none of the optional fields are used,
+	// it will be sent as a message when the button is pressed.
+	Text string `json:"text"`
+	// RequestContact if True, the user's phone number will be sent
+	// as a contact when the button is pressed.
+	// Available in private chats only.
+	//
+	// optional
+	RequestContact bool `json:"request_contact,omitempty"`
+	// RequestLocation if True, the user's current location will be sent when
+	// the button is pressed.
+	// Available in private chats only.
+	//
+	// optional
+	RequestLocation bool `json:"request_location,omitempty"`
+	// RequestPoll if True, the user will be asked to create a poll and send it
+	// to the bot when the button is pressed. Available in private chats only
+	//
+	// optional
+	RequestPoll *KeyboardButtonPollType `json:"request_poll,omitempty"`
+	// WebApp if specified, the described Web App will be launched when the
+	// button is pressed. The Web App will be able to send a “web_app_data”
+	// service message. Available in private chats only.
+	//
+	// optional
+	WebApp *WebAppInfo `json:"web_app,omitempty"`
 }
 
 // KeyboardButtonPollType represents type of poll, which is allowed to
@@ -537,6 +1568,30 @@ type InlineKeyboardMarkup struct {
 	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
 }
 
+// Validate checks that every button sets exactly one action, that callback
+// data is 1-64 bytes long and that a Pay or CallbackGame button is the first
+// button in the first row.
+func (markup InlineKeyboardMarkup) Validate() error {
+	for i, row := range markup.InlineKeyboard {
+		for j, button := range row {
+			actions := button.actions()
+			if len(actions) != 1 {
+				return fmt.Errorf("inline keyboard button %q must set exactly one action, got %d (%s)", button.Text, len(actions), strings.Join(actions, ", "))
+			}
+
+			if data := button.CallbackData; data != nil && (len(*data) < 1 || len(*data) > 64) {
+				return fmt.Errorf("callback data for inline keyboard button %q must be 1-64 bytes, got %d", button.Text, len(*data))
+			}
+
+			if (button.Pay || button.CallbackGame != nil) && (i != 0 || j != 0) {
+				return fmt.Errorf("inline keyboard button %q sets %s and must be the first button in the first row", button.Text, actions[0])
+			}
+		}
+	}
+
+	return nil
+}
+
 // InlineKeyboardButton represents one button of an inline keyboard. You must
 // use exactly one of the optional fields.
 //
@@ -556,6 +1611,12 @@ type InlineKeyboardButton struct {
 	//
 	// optional
 	LoginURL *LoginURL `json:"login_url,omitempty"`
+	// WebApp description of the Web App that will be launched when the user
+	// presses the button. Available only in private chats between a user and
+	// the bot.
+	//
+	// optional
+	WebApp *WebAppInfo `json:"web_app,omitempty"`
 	// CallbackData data to be sent in a callback query to the bot when button is pressed, 1-64 bytes.
 	//
 	// optional
@@ -593,9 +1654,76 @@ type InlineKeyboardButton struct {
 	Pay bool `json:"pay,omitempty"`
 }
 
+// KeyVal is a label and its callback data, used to build an inline keyboard
+// in a fixed order.
+type KeyVal struct {
+	Label string
+	Data  string
+}
+
+// actions lists the action fields that are set on the button.
+func (button InlineKeyboardButton) actions() []string {
+	var actions []string
+
+	if button.URL != nil {
+		actions = append(actions, "url")
+	}
+	if button.LoginURL != nil {
+		actions = append(actions, "login_url")
+	}
+	if button.WebApp != nil {
+		actions = append(actions, "web_app")
+	}
+	if button.CallbackData != nil {
+		actions = append(actions, "callback_data")
+	}
+	if button.SwitchInlineQuery != nil {
+		actions = append(actions, "switch_inline_query")
+	}
+	if button.SwitchInlineQueryCurrentChat != nil {
+		actions = append(actions, "switch_inline_query_current_chat")
+	}
+	if button.CallbackGame != nil {
+		actions = append(actions, "callback_game")
+	}
+	if button.Pay {
+		actions = append(actions, "pay")
+	}
+
+	return actions
+}
+
+// WebAppInfo describes a Web App.
+type WebAppInfo struct {
+	// URL is an HTTPS URL of a Web App to be opened with additional data as
+	// specified in Initializing Web Apps
+	URL string `json:"url"`
+}
+
+// WebAppData describes data sent from a Web App to the bot.
+type WebAppData struct {
+	// Data is the data. Be aware that a bad client can send arbitrary data in
+	// this field.
+	Data string `json:"data"`
+	// ButtonText is the text of the web_app keyboard button from which the
+	// Web App was opened. Be aware that a bad client can send arbitrary data
+	// in this field.
+	ButtonText string `json:"button_text"`
+}
+
 // CallbackGame is for starting a game in an inline keyboard button.
 type CallbackGame struct{}
 
+// GameHighScore is a user's score and position on the leaderboard.
+type GameHighScore struct {
+	// Position in high score table for the game
+	Position int `json:"position"`
+	// User user
+	User User `json:"user"`
+	// Score score
+	Score int `json:"score"`
+}
+
 // LoginURL represents a parameter of the inline keyboard button used to
 // automatically authorize a user. Serves as a great replacement for the
 // Telegram Login Widget when the user is coming from Telegram. All the user
@@ -676,3 +1804,602 @@ type ForceReply struct {
 	// optional
 	Selective bool `json:"selective,omitempty"`
 }
+
+// BaseInputMedia is a base type for the InputMedia types.
+type BaseInputMedia struct {
+	// Type of the result.
+	Type string `json:"type"`
+	// Media file to send. Pass a file_id to send a file
+	// that exists on the Telegram servers (recommended),
+	// pass an HTTP URL for Telegram to get a file from the Internet,
+	// or pass “attach://<file_attach_name>” to upload a new one
+	// using multipart/form-data under <file_attach_name> name.
+	Media RequestFileData `json:"media"`
+	// Caption of the media to be sent, 0-1024 characters after entities parsing.
+	//
+	// optional
+	Caption string `json:"caption,omitempty"`
+	// ParseMode mode for parsing entities in the media caption.
+	//
+	// optional
+	ParseMode string `json:"parse_mode,omitempty"`
+	// CaptionEntities is a list of special entities that appear in the caption,
+	// which can be specified instead of parse_mode
+	//
+	// optional
+	CaptionEntities []MessageEntity `json:"caption_entities,omitempty"`
+}
+
+// InputMediaPhoto is a photo to send as part of a media group.
+type InputMediaPhoto struct {
+	BaseInputMedia
+}
+
+// InputMediaVideo is a video to send as part of a media group.
+type InputMediaVideo struct {
+	BaseInputMedia
+	// Thumbnail of the file sent; can be ignored if thumbnail generation for
+	// the file is supported server-side.
+	//
+	// optional
+	Thumb RequestFileData `json:"thumb,omitempty"`
+	// Width video width
+	//
+	// optional
+	Width int `json:"width,omitempty"`
+	// Height video height
+	//
+	// optional
+	Height int `json:"height,omitempty"`
+	// Duration video duration
+	//
+	// optional
+	Duration int `json:"duration,omitempty"`
+	// SupportsStreaming pass True, if the uploaded video is suitable for streaming.
+	//
+	// optional
+	SupportsStreaming bool `json:"supports_streaming,omitempty"`
+}
+
+// InputMediaAudio is an audio file to send as part of a media group.
+type InputMediaAudio struct {
+	BaseInputMedia
+	// Thumbnail of the file sent; can be ignored if thumbnail generation for
+	// the file is supported server-side.
+	//
+	// optional
+	Thumb RequestFileData `json:"thumb,omitempty"`
+	// Duration of the audio in seconds
+	//
+	// optional
+	Duration int `json:"duration,omitempty"`
+	// Performer of the audio
+	//
+	// optional
+	Performer string `json:"performer,omitempty"`
+	// Title of the audio
+	//
+	// optional
+	Title string `json:"title,omitempty"`
+}
+
+// InputMediaDocument is a general file to send as part of a media group.
+type InputMediaDocument struct {
+	BaseInputMedia
+	// Thumbnail of the file sent; can be ignored if thumbnail generation for
+	// the file is supported server-side.
+	//
+	// optional
+	Thumb RequestFileData `json:"thumb,omitempty"`
+	// DisableContentTypeDetection disables automatic server-side content type
+	// detection for files uploaded using multipart/form-data. Always true, if
+	// the document is sent as part of an album
+	//
+	// optional
+	DisableContentTypeDetection bool `json:"disable_content_type_detection,omitempty"`
+}
+
+// InlineQuery is a Query from Telegram for an inline request.
+type InlineQuery struct {
+	// ID unique identifier for this query
+	ID string `json:"id"`
+	// From sender
+	From *User `json:"from"`
+	// Query text of the query (up to 256 characters).
+	Query string `json:"query"`
+	// Offset of the results to be returned, can be controlled by the bot.
+	Offset string `json:"offset"`
+	// Type of the chat, from which the inline query was sent. Can be either
+	// “sender” for a private chat with the inline query sender, “private”,
+	// “group”, “supergroup”, or “channel”. The chat type should be always known
+	// for requests sent from official clients and most third-party clients,
+	// unless the request was sent from a secret chat
+	//
+	// optional
+	ChatType string `json:"chat_type,omitempty"`
+	// Location sender location, only for bots that request user location.
+	//
+	// optional
+	Location *Location `json:"location,omitempty"`
+}
+
+// InlineQueryResultArticle represents a link to an article or web page.
+type InlineQueryResultArticle struct {
+	// Type of the result, must be "article".
+	Type string `json:"type"`
+	// ID unique identifier for this result, 1-64 Bytes.
+	ID string `json:"id"`
+	// Title of the result
+	Title string `json:"title"`
+	// InputMessageContent content of the message to be sent.
+	InputMessageContent interface{} `json:"input_message_content,omitempty"`
+	// ReplyMarkup Inline keyboard attached to the message.
+	//
+	// optional
+	ReplyMarkup *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	// URL of the result.
+	//
+	// optional
+	URL string `json:"url,omitempty"`
+	// Description short description of the result.
+	//
+	// optional
+	Description string `json:"description,omitempty"`
+}
+
+// InlineQueryResultPhoto is an inline query response photo.
+type InlineQueryResultPhoto struct {
+	// Type of the result, must be "photo".
+	Type string `json:"type"`
+	// ID unique identifier for this result, 1-64 bytes.
+	ID string `json:"id"`
+	// URL a valid URL of the photo. Photo must be in jpeg format.
+	// Photo size must not exceed 5MB.
+	URL string `json:"photo_url"`
+	// ThumbURL url of the thumbnail for the photo.
+	ThumbURL string `json:"thumbnail_url"`
+	// Width of the photo
+	//
+	// optional
+	Width int `json:"photo_width,omitempty"`
+	// Height of the photo
+	//
+	// optional
+	Height int `json:"photo_height,omitempty"`
+	// Title for the result
+	//
+	// optional
+	Title string `json:"title,omitempty"`
+	// Description short description of the result
+	//
+	// optional
+	Description string `json:"description,omitempty"`
+	// Caption of the photo to be sent, 0-1024 characters after entities parsing.
+	//
+	// optional
+	Caption string `json:"caption,omitempty"`
+	// ParseMode mode for parsing entities in the photo caption.
+	//
+	// optional
+	ParseMode string `json:"parse_mode,omitempty"`
+	// CaptionEntities is a list of special entities that appear in the caption,
+	// which can be specified instead of parse_mode
+	//
+	// optional
+	CaptionEntities []MessageEntity `json:"caption_entities,omitempty"`
+	// ReplyMarkup inline keyboard attached to the message.
+	//
+	// optional
+	ReplyMarkup *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	// InputMessageContent content of the message to be sent instead of the photo.
+	//
+	// optional
+	InputMessageContent interface{} `json:"input_message_content,omitempty"`
+}
+
+// InputTextMessageContent contains text for displaying
+// as an inline query result.
+type InputTextMessageContent struct {
+	// Text of the message to be sent, 1-4096 characters
+	Text string `json:"message_text"`
+	// ParseMode mode for parsing entities in the message text.
+	//
+	// optional
+	ParseMode string `json:"parse_mode,omitempty"`
+	// Entities is a list of special entities that appear in message text, which
+	// can be specified instead of parse_mode
+	//
+	// optional
+	Entities []MessageEntity `json:"entities,omitempty"`
+}
+
+// PreparedInlineMessage describes an inline message to be sent by a user of a
+// Mini App.
+type PreparedInlineMessage struct {
+	// ID is the unique identifier of the prepared message
+	ID string `json:"id"`
+	// ExpirationDate is the expiration date of the prepared message, in Unix
+	// time. Expired prepared messages can no longer be used
+	ExpirationDate int64 `json:"expiration_date"`
+}
+
+// ChatInviteLink represents an invite link for a chat.
+type ChatInviteLink struct {
+	// InviteLink is the invite link. If the link was created by another chat
+	// administrator, then the second part of the link will be replaced with
+	// “…”.
+	InviteLink string `json:"invite_link"`
+	// Creator of the link
+	Creator User `json:"creator"`
+	// CreatesJoinRequest is true, if users joining the chat via the link need
+	// to be approved by chat administrators
+	//
+	// optional
+	CreatesJoinRequest bool `json:"creates_join_request,omitempty"`
+	// IsPrimary is true, if the link is primary
+	IsPrimary bool `json:"is_primary"`
+	// IsRevoked is true, if the link is revoked
+	IsRevoked bool `json:"is_revoked"`
+	// Name is the invite link name
+	//
+	// optional
+	Name string `json:"name,omitempty"`
+	// ExpireDate is the point in time (Unix timestamp) when the link will
+	// expire or has been expired
+	//
+	// optional
+	ExpireDate int `json:"expire_date,omitempty"`
+	// MemberLimit is the maximum number of users that can be members of the
+	// chat simultaneously after joining the chat via this invite link; 1-99999
+	//
+	// optional
+	MemberLimit int `json:"member_limit,omitempty"`
+	// PendingJoinRequestCount is the number of pending join requests created
+	// using this link
+	//
+	// optional
+	PendingJoinRequestCount int `json:"pending_join_request_count,omitempty"`
+}
+
+// ChatMemberUpdated represents changes in the status of a chat member.
+type ChatMemberUpdated struct {
+	// Chat the user belongs to
+	Chat Chat `json:"chat"`
+	// From is the performer of the action, which resulted in the change
+	From User `json:"from"`
+	// Date the change was done in Unix time
+	Date int `json:"date"`
+	// OldChatMember is the previous information about the chat member
+	OldChatMember ChatMember `json:"old_chat_member"`
+	// NewChatMember is the new information about the chat member
+	NewChatMember ChatMember `json:"new_chat_member"`
+	// InviteLink is the chat invite link, which was used by the user to join
+	// the chat; for joining by invite link events only
+	//
+	// optional
+	InviteLink *ChatInviteLink `json:"invite_link,omitempty"`
+}
+
+// ChatJoinRequest represents a join request sent to a chat.
+type ChatJoinRequest struct {
+	// Chat to which the request was sent
+	Chat Chat `json:"chat"`
+	// From is the user that sent the join request
+	From User `json:"from"`
+	// UserChatID is the identifier of a private chat with the user who sent
+	// the join request. The bot can use it to send messages until the join
+	// request is processed, assuming no other administrator contacted the
+	// user.
+	UserChatID int64 `json:"user_chat_id"`
+	// Date the request was sent in Unix time
+	Date int `json:"date"`
+	// Bio of the user
+	//
+	// optional
+	Bio string `json:"bio,omitempty"`
+	// InviteLink is the chat invite link that was used by the user to send
+	// the join request
+	//
+	// optional
+	InviteLink *ChatInviteLink `json:"invite_link,omitempty"`
+}
+
+// ChatMember contains information about one member of a chat.
+type ChatMember struct {
+	// User information about the user
+	User *User `json:"user"`
+	// Status the member's status in the chat.
+	// Can be
+	//  "creator",
+	//  "administrator",
+	//  "member",
+	//  "restricted",
+	//  "left" or
+	//  "kicked"
+	Status string `json:"status"`
+	// CustomTitle owner and administrators only. Custom title for this user
+	//
+	// optional
+	CustomTitle string `json:"custom_title,omitempty"`
+	// IsAnonymous owner and administrators only. True, if the user's presence
+	// in the chat is hidden
+	//
+	// optional
+	IsAnonymous bool `json:"is_anonymous,omitempty"`
+	// UntilDate restricted and kicked only.
+	// Date when restrictions will be lifted for this user;
+	// unix time.
+	//
+	// optional
+	UntilDate int64 `json:"until_date,omitempty"`
+	// CanBeEdited is true, if the bot is allowed to edit administrator privileges of that user
+	// (administrators only)
+	//
+	// optional
+	CanBeEdited bool `json:"can_be_edited,omitempty"`
+	// CanManageChat is true, if the administrator can access the chat event log, chat statistics,
+	// message statistics in channels, see channel members, see anonymous
+	// administrators in supergroups and ignore slow mode (administrators only)
+	//
+	// optional
+	CanManageChat bool `json:"can_manage_chat,omitempty"`
+	// CanPostMessages is true, if the administrator can post in the channel
+	// (channels only)
+	//
+	// optional
+	CanPostMessages bool `json:"can_post_messages,omitempty"`
+	// CanEditMessages is true, if the administrator can edit messages of other users and can pin
+	// messages (channels only)
+	//
+	// optional
+	CanEditMessages bool `json:"can_edit_messages,omitempty"`
+	// CanDeleteMessages is true, if the administrator can delete messages of other users
+	// (administrators only)
+	//
+	// optional
+	CanDeleteMessages bool `json:"can_delete_messages,omitempty"`
+	// CanManageVideoChats is true, if the administrator can manage video chats
+	// (administrators only)
+	//
+	// optional
+	CanManageVideoChats bool `json:"can_manage_video_chats,omitempty"`
+	// CanRestrictMembers is true, if the administrator can restrict, ban or unban chat members
+	// (administrators only)
+	//
+	// optional
+	CanRestrictMembers bool `json:"can_restrict_members,omitempty"`
+	// CanPromoteMembers is true, if the administrator can add new administrators with a subset of their
+	// own privileges or demote administrators that they have promoted
+	// (administrators only)
+	//
+	// optional
+	CanPromoteMembers bool `json:"can_promote_members,omitempty"`
+	// CanChangeInfo is true, if the user is allowed to change the chat title, photo and other
+	// settings (administrators and restricted only)
+	//
+	// optional
+	CanChangeInfo bool `json:"can_change_info,omitempty"`
+	// CanInviteUsers is true, if the user is allowed to invite new users to the chat
+	// (administrators and restricted only)
+	//
+	// optional
+	CanInviteUsers bool `json:"can_invite_users,omitempty"`
+	// CanPinMessages is true, if the user is allowed to pin messages
+	// (administrators and restricted only)
+	//
+	// optional
+	CanPinMessages bool `json:"can_pin_messages,omitempty"`
+	// CanManageTopics is true, if the user is allowed to create, rename, close, and reopen forum
+	// topics (administrators and restricted only)
+	//
+	// optional
+	CanManageTopics bool `json:"can_manage_topics,omitempty"`
+	// IsMember is true, if the user is a member of the chat at the moment of the request
+	// (restricted only)
+	//
+	// optional
+	IsMember bool `json:"is_member,omitempty"`
+	// CanSendMessages is true, if the user is allowed to send text messages, contacts, invoices,
+	// locations and venues (restricted only)
+	//
+	// optional
+	CanSendMessages bool `json:"can_send_messages,omitempty"`
+	// CanSendAudios is true, if the user is allowed to send audios (restricted only)
+	//
+	// optional
+	CanSendAudios bool `json:"can_send_audios,omitempty"`
+	// CanSendDocuments is true, if the user is allowed to send documents (restricted only)
+	//
+	// optional
+	CanSendDocuments bool `json:"can_send_documents,omitempty"`
+	// CanSendPhotos is true, if the user is allowed to send photos (restricted only)
+	//
+	// optional
+	CanSendPhotos bool `json:"can_send_photos,omitempty"`
+	// CanSendVideos is true, if the user is allowed to send videos (restricted only)
+	//
+	// optional
+	CanSendVideos bool `json:"can_send_videos,omitempty"`
+	// CanSendVideoNotes is true, if the user is allowed to send video notes (restricted only)
+	//
+	// optional
+	CanSendVideoNotes bool `json:"can_send_video_notes,omitempty"`
+	// CanSendVoiceNotes is true, if the user is allowed to send voice notes (restricted only)
+	//
+	// optional
+	CanSendVoiceNotes bool `json:"can_send_voice_notes,omitempty"`
+	// CanSendPolls is true, if the user is allowed to send polls (restricted only)
+	//
+	// optional
+	CanSendPolls bool `json:"can_send_polls,omitempty"`
+	// CanSendOtherMessages is true, if the user is allowed to send animations, games, stickers and use
+	// inline bots (restricted only)
+	//
+	// optional
+	CanSendOtherMessages bool `json:"can_send_other_messages,omitempty"`
+	// CanAddWebPagePreviews is true, if the user is allowed to add web page previews to their messages
+	// (restricted only)
+	//
+	// optional
+	CanAddWebPagePreviews bool `json:"can_add_web_page_previews,omitempty"`
+}
+
+// IsCreator returns if the ChatMember was the creator of the chat.
+func (chat ChatMember) IsCreator() bool { return chat.Status == "creator" }
+
+// IsAdministrator returns if the ChatMember is a chat administrator.
+func (chat ChatMember) IsAdministrator() bool { return chat.Status == "administrator" }
+
+// ChatPermissions describes actions that a non-administrator user is
+// allowed to take in a chat. All fields are optional.
+type ChatPermissions struct {
+	// CanSendMessages is true, if the user is allowed to send text messages,
+	// contacts, locations and venues
+	CanSendMessages bool `json:"can_send_messages,omitempty"`
+	// CanSendAudios is true, if the user is allowed to send audios
+	CanSendAudios bool `json:"can_send_audios,omitempty"`
+	// CanSendDocuments is true, if the user is allowed to send documents
+	CanSendDocuments bool `json:"can_send_documents,omitempty"`
+	// CanSendPhotos is true, if the user is allowed to send photos
+	CanSendPhotos bool `json:"can_send_photos,omitempty"`
+	// CanSendVideos is true, if the user is allowed to send videos
+	CanSendVideos bool `json:"can_send_videos,omitempty"`
+	// CanSendVideoNotes is true, if the user is allowed to send video notes
+	CanSendVideoNotes bool `json:"can_send_video_notes,omitempty"`
+	// CanSendVoiceNotes is true, if the user is allowed to send voice notes
+	CanSendVoiceNotes bool `json:"can_send_voice_notes,omitempty"`
+	// CanSendPolls is true, if the user is allowed to send polls
+	CanSendPolls bool `json:"can_send_polls,omitempty"`
+	// CanSendOtherMessages is true, if the user is allowed to send animations,
+	// games, stickers and use inline bots
+	CanSendOtherMessages bool `json:"can_send_other_messages,omitempty"`
+	// CanAddWebPagePreviews is true, if the user is allowed to add web page
+	// previews to their messages
+	CanAddWebPagePreviews bool `json:"can_add_web_page_previews,omitempty"`
+	// CanChangeInfo is true, if the user is allowed to change the chat title,
+	// photo and other settings. Ignored in public supergroups
+	CanChangeInfo bool `json:"can_change_info,omitempty"`
+	// CanInviteUsers is true, if the user is allowed to invite new users to the
+	// chat
+	CanInviteUsers bool `json:"can_invite_users,omitempty"`
+	// CanPinMessages is true, if the user is allowed to pin messages. Ignored
+	// in public supergroups
+	CanPinMessages bool `json:"can_pin_messages,omitempty"`
+	// CanManageTopics is true, if the user is allowed to create forum topics
+	CanManageTopics bool `json:"can_manage_topics,omitempty"`
+}
+
+// MessageOrigin describes the origin of a forwarded message.
+//
+// It contains the fields for all types of origins, different types only use
+// specific fields.
+type MessageOrigin struct {
+	// Type of the origin, one of "user", "hidden_user", "chat" or "channel"
+	Type string `json:"type"`
+	// Date the original message was sent in Unix time
+	Date int `json:"date"`
+	// SenderUser is the user that sent the original message, for "user"
+	// origins only
+	//
+	// optional
+	SenderUser *User `json:"sender_user,omitempty"`
+	// SenderUserName is the name of the user that sent the original message,
+	// for "hidden_user" origins only
+	//
+	// optional
+	SenderUserName string `json:"sender_user_name,omitempty"`
+	// SenderChat is the chat that sent the original message, for "chat"
+	// origins only
+	//
+	// optional
+	SenderChat *Chat `json:"sender_chat,omitempty"`
+	// Chat is the channel the original message was sent to, for "channel"
+	// origins only
+	//
+	// optional
+	Chat *Chat `json:"chat,omitempty"`
+	// MessageID is the unique message identifier inside the channel, for
+	// "channel" origins only
+	//
+	// optional
+	MessageID int `json:"message_id,omitempty"`
+	// AuthorSignature is the signature of the original post author, for
+	// "chat" and "channel" origins only
+	//
+	// optional
+	AuthorSignature string `json:"author_signature,omitempty"`
+}
+
+// ReactionType describes the type of a reaction.
+//
+// It contains the fields for all types of reactions, different types only
+// use specific (or no) fields.
+type ReactionType struct {
+	// Type of the reaction, one of "emoji", "custom_emoji" or "paid"
+	Type string `json:"type"`
+	// Emoji is the reaction emoji, for "emoji" reactions only
+	//
+	// optional
+	Emoji string `json:"emoji,omitempty"`
+	// CustomEmojiID is the custom emoji identifier, for "custom_emoji"
+	// reactions only
+	//
+	// optional
+	CustomEmojiID string `json:"custom_emoji_id,omitempty"`
+}
+
+// ReactionCount represents a reaction added to a message along with the
+// number of times it was added.
+type ReactionCount struct {
+	// Type of the reaction
+	Type ReactionType `json:"type"`
+	// TotalCount is the number of times the reaction was added
+	TotalCount int `json:"total_count"`
+}
+
+// PaidMediaPurchased contains information about a paid media purchase.
+type PaidMediaPurchased struct {
+	// From is the user who purchased the media
+	From *User `json:"from"`
+	// PaidMediaPayload is the bot-specified paid media payload
+	PaidMediaPayload string `json:"paid_media_payload"`
+}
+
+// MessageReactionUpdated represents a change of a reaction on a message
+// performed by a user.
+type MessageReactionUpdated struct {
+	// Chat containing the message the user reacted to
+	Chat *Chat `json:"chat"`
+	// MessageID is the unique identifier of the message inside the chat
+	MessageID int `json:"message_id"`
+	// User that changed the reaction, if the user isn't anonymous
+	//
+	// optional
+	User *User `json:"user,omitempty"`
+	// ActorChat is the chat on behalf of which the reaction was changed, if
+	// the user is anonymous
+	//
+	// optional
+	ActorChat *Chat `json:"actor_chat,omitempty"`
+	// Date of the change in Unix time
+	Date int `json:"date"`
+	// OldReaction is the previous list of reaction types that were set by
+	// the user
+	OldReaction []ReactionType `json:"old_reaction"`
+	// NewReaction is the new list of reaction types that have been set by
+	// the user
+	NewReaction []ReactionType `json:"new_reaction"`
+}
+
+// MessageReactionCountUpdated represents reaction changes on a message with
+// anonymous reactions.
+type MessageReactionCountUpdated struct {
+	// Chat containing the message
+	Chat *Chat `json:"chat"`
+	// MessageID is the unique message identifier inside the chat
+	MessageID int `json:"message_id"`
+	// Date of the change in Unix time
+	Date int `json:"date"`
+	// Reactions is the list of reactions that are present on the message
+	Reactions []ReactionCount `json:"reactions"`
+}
@@ -0,0 +1,739 @@
+package tgapimanager
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessageAnonymousAdmin(t *testing.T) {
+	var message Message
+	err := json.Unmarshal([]byte(`{
+		"message_id": 1,
+		"date": 0,
+		"chat": {"id": -100123},
+		"sender_chat": {"id": -100123},
+		"text": "hello"
+	}`), &message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !message.IsFromAnonymousAdmin() {
+		t.Error("expected message from anonymous admin")
+	}
+
+	user, chat := message.EffectiveSender()
+	if user != nil {
+		t.Errorf("expected no user, got %v", user)
+	}
+	if chat == nil || chat.ID != -100123 {
+		t.Errorf("expected chat -100123 as sender, got %v", chat)
+	}
+
+	message.SenderChat = nil
+	message.From = &User{ID: 7}
+
+	if message.IsFromAnonymousAdmin() {
+		t.Error("expected regular message")
+	}
+	if user, _ := message.EffectiveSender(); user == nil || user.ID != 7 {
+		t.Errorf("expected user 7 as sender, got %v", user)
+	}
+}
+
+func TestMessageCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  Message
+		command  string
+		argument string
+	}{
+		{
+			name: "command with arguments",
+			message: Message{
+				Text:     "/start arg1 arg2",
+				Entities: []MessageEntity{{Type: "bot_command", Offset: 0, Length: 6}},
+			},
+			command:  "start",
+			argument: "arg1 arg2",
+		},
+		{
+			name: "command with bot name",
+			message: Message{
+				Text:     "/help@my_bot",
+				Entities: []MessageEntity{{Type: "bot_command", Offset: 0, Length: 12}},
+			},
+			command: "help",
+		},
+		{
+			name: "emoji arguments",
+			message: Message{
+				Text:     "/vote 🎉🎉 yes",
+				Entities: []MessageEntity{{Type: "bot_command", Offset: 0, Length: 5}},
+			},
+			command:  "vote",
+			argument: "🎉🎉 yes",
+		},
+		{
+			name: "command after emoji",
+			message: Message{
+				Text:     "🎉 /start",
+				Entities: []MessageEntity{{Type: "bot_command", Offset: 3, Length: 6}},
+			},
+		},
+		{
+			name: "other entity",
+			message: Message{
+				Text:     "@someone hi",
+				Entities: []MessageEntity{{Type: "mention", Offset: 0, Length: 8}},
+			},
+		},
+		{
+			name:    "plain text",
+			message: Message{Text: "/start"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.message.IsCommand(); got != (test.command != "") {
+				t.Errorf("expected IsCommand %t, got %t", test.command != "", got)
+			}
+			if got := test.message.Command(); got != test.command {
+				t.Errorf("expected command %q, got %q", test.command, got)
+			}
+			if got := test.message.CommandArguments(); got != test.argument {
+				t.Errorf("expected arguments %q, got %q", test.argument, got)
+			}
+		})
+	}
+}
+
+func TestAPIErrorInspection(t *testing.T) {
+	flood := &Error{
+		Code:               429,
+		Message:            "Too Many Requests: retry after 5",
+		ResponseParameters: ResponseParameters{RetryAfter: 5},
+	}
+
+	apiErr, ok := AsAPIError(Wrap("can't send a message", Wrap("can't do a request", flood)))
+	if !ok || apiErr != flood {
+		t.Fatalf("expected the wrapped flood error, got %v", apiErr)
+	}
+	if !apiErr.IsFloodError() || apiErr.RetryAfterDuration() != 5*time.Second {
+		t.Errorf("expected a flood error to retry after 5s, got %d and %s", apiErr.Code, apiErr.RetryAfterDuration())
+	}
+	if apiErr.IsChatNotFound() {
+		t.Error("expected a flood error not to be chat not found")
+	}
+
+	notFound := Error{Code: 400, Message: "Bad Request: chat not found"}
+	if !notFound.IsChatNotFound() || notFound.IsFloodError() || notFound.RetryAfterDuration() != 0 {
+		t.Errorf("unexpected inspection of %v", notFound)
+	}
+
+	if _, ok := AsAPIError(Wrap("can't do a request", io.ErrUnexpectedEOF)); ok {
+		t.Error("expected no API error in a network error")
+	}
+}
+
+func TestInlineKeyboardMarkupValidate(t *testing.T) {
+	url := "https://example.com"
+	data := "data"
+
+	tests := []struct {
+		name   string
+		markup InlineKeyboardMarkup
+		err    string
+	}{
+		{
+			"url and callback data",
+			NewInlineKeyboardMarkup(NewInlineKeyboardRow(InlineKeyboardButton{Text: "Both", URL: &url, CallbackData: &data})),
+			"exactly one action, got 2 (url, callback_data)",
+		},
+		{
+			"callback data too long",
+			NewInlineKeyboardMarkup(NewInlineKeyboardRow(NewInlineKeyboardButtonData("Long", strings.Repeat("a", 65)))),
+			"must be 1-64 bytes, got 65",
+		},
+		{
+			"empty callback data",
+			NewInlineKeyboardMarkup(NewInlineKeyboardRow(NewInlineKeyboardButtonData("Empty", ""))),
+			"must be 1-64 bytes, got 0",
+		},
+		{
+			"pay not first",
+			NewInlineKeyboardMarkup(NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("First", data),
+				InlineKeyboardButton{Text: "Pay", Pay: true},
+			)),
+			"must be the first button in the first row",
+		},
+		{
+			"valid",
+			NewInlineKeyboardMarkup(NewInlineKeyboardRow(
+				InlineKeyboardButton{Text: "Pay", Pay: true},
+				NewInlineKeyboardButtonURL("Site", url),
+			)),
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			msg := NewMessage(1, "keyboard")
+			msg.ReplyMarkup = test.markup
+
+			_, err := msg.params()
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("expected error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestUpdateSentFromAndFromChat(t *testing.T) {
+	tests := []struct {
+		name   string
+		update string
+		userID int64
+		chatID int64
+	}{
+		{
+			name:   "message",
+			update: `{"update_id": 1, "message": {"message_id": 1, "date": 0, "from": {"id": 7}, "chat": {"id": 7}}}`,
+			userID: 7,
+			chatID: 7,
+		},
+		{
+			name:   "edited message",
+			update: `{"update_id": 1, "edited_message": {"message_id": 1, "date": 0, "from": {"id": 7}, "chat": {"id": -100}}}`,
+			userID: 7,
+			chatID: -100,
+		},
+		{
+			name:   "channel post",
+			update: `{"update_id": 1, "channel_post": {"message_id": 1, "date": 0, "sender_chat": {"id": -200}, "chat": {"id": -200, "type": "channel"}}}`,
+			chatID: -200,
+		},
+		{
+			name:   "edited channel post",
+			update: `{"update_id": 1, "edited_channel_post": {"message_id": 1, "date": 0, "chat": {"id": -200, "type": "channel"}}}`,
+			chatID: -200,
+		},
+		{
+			name:   "callback query",
+			update: `{"update_id": 1, "callback_query": {"id": "q", "from": {"id": 8}, "message": {"message_id": 1, "date": 0, "chat": {"id": -100}}}}`,
+			userID: 8,
+			chatID: -100,
+		},
+		{
+			name:   "inline query",
+			update: `{"update_id": 1, "inline_query": {"id": "q", "from": {"id": 9}, "query": "", "offset": ""}}`,
+			userID: 9,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var update Update
+			if err := json.Unmarshal([]byte(test.update), &update); err != nil {
+				t.Fatal(err)
+			}
+
+			user := update.SentFrom()
+			if test.userID == 0 && user != nil {
+				t.Errorf("expected no user, got %v", user)
+			} else if test.userID != 0 && (user == nil || user.ID != test.userID) {
+				t.Errorf("expected user %d, got %v", test.userID, user)
+			}
+
+			chat := update.FromChat()
+			if test.chatID == 0 && chat != nil {
+				t.Errorf("expected no chat, got %v", chat)
+			} else if test.chatID != 0 && (chat == nil || chat.ID != test.chatID) {
+				t.Errorf("expected chat %d, got %v", test.chatID, chat)
+			}
+		})
+	}
+}
+
+func TestChatJoinRequest(t *testing.T) {
+	var update Update
+	err := json.Unmarshal([]byte(`{
+		"update_id": 1,
+		"chat_join_request": {
+			"chat": {"id": -100, "type": "supergroup", "title": "Members"},
+			"from": {"id": 7, "first_name": "Applicant"},
+			"user_chat_id": 7,
+			"date": 1700000000,
+			"bio": "Hello",
+			"invite_link": {"invite_link": "https://t.me/+trial", "creator": {"id": 1}, "creates_join_request": true, "is_primary": false, "is_revoked": false}
+		}
+	}`), &update)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := update.ChatJoinRequest
+	if request == nil || request.Bio != "Hello" || request.UserChatID != 7 {
+		t.Fatalf("unexpected join request %+v", request)
+	}
+	if request.InviteLink == nil || !request.InviteLink.CreatesJoinRequest {
+		t.Errorf("expected the invite link to be decoded, got %+v", request.InviteLink)
+	}
+	if user := update.SentFrom(); user == nil || user.ID != 7 {
+		t.Errorf("expected sender 7, got %v", user)
+	}
+	if chat := update.FromChat(); chat == nil || chat.ID != -100 {
+		t.Errorf("expected chat -100, got %v", chat)
+	}
+
+	params, err := ApproveChatJoinRequestConfig{ChatConfig{ChatID: request.Chat.ID}, request.From.ID}.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["chat_id"] != "-100" || params["user_id"] != "7" {
+		t.Errorf("unexpected approve params %v", params)
+	}
+}
+
+func TestMessageReactionUpdated(t *testing.T) {
+	var update Update
+	err := json.Unmarshal([]byte(`{
+		"update_id": 1,
+		"message_reaction": {
+			"chat": {"id": -100},
+			"message_id": 7,
+			"user": {"id": 3},
+			"date": 0,
+			"old_reaction": [],
+			"new_reaction": [{"type": "emoji", "emoji": "🔥"}]
+		}
+	}`), &update)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reaction := update.MessageReaction
+	if reaction == nil || reaction.MessageID != 7 || len(reaction.OldReaction) != 0 {
+		t.Fatalf("unexpected reaction update %+v", reaction)
+	}
+	if len(reaction.NewReaction) != 1 || reaction.NewReaction[0].Emoji != "🔥" {
+		t.Errorf("unexpected new reaction %+v", reaction.NewReaction)
+	}
+	if user := update.SentFrom(); user == nil || user.ID != 3 {
+		t.Errorf("expected sender 3, got %v", user)
+	}
+}
+
+func TestMessageReactionCountUpdated(t *testing.T) {
+	var update Update
+	err := json.Unmarshal([]byte(`{
+		"update_id": 1,
+		"message_reaction_count": {
+			"chat": {"id": -100},
+			"message_id": 7,
+			"date": 0,
+			"reactions": [
+				{"type": {"type": "emoji", "emoji": "👍"}, "total_count": 42},
+				{"type": {"type": "custom_emoji", "custom_emoji_id": "5368324170671202286"}, "total_count": 3}
+			]
+		}
+	}`), &update)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reactions := update.MessageReactionCount.Reactions
+	if len(reactions) != 2 {
+		t.Fatalf("expected two reactions, got %d", len(reactions))
+	}
+	if reactions[0].Type.Emoji != "👍" || reactions[0].TotalCount != 42 {
+		t.Errorf("unexpected emoji reaction %+v", reactions[0])
+	}
+	if reactions[1].Type.CustomEmojiID != "5368324170671202286" || reactions[1].TotalCount != 3 {
+		t.Errorf("unexpected custom emoji reaction %+v", reactions[1])
+	}
+}
+
+func TestPurchasedPaidMedia(t *testing.T) {
+	var update Update
+	err := json.Unmarshal([]byte(`{
+		"update_id": 1,
+		"purchased_paid_media": {
+			"from": {"id": 42, "first_name": "Buyer"},
+			"paid_media_payload": "order-123"
+		}
+	}`), &update)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	purchase := update.PurchasedPaidMedia
+	if purchase == nil {
+		t.Fatal("expected a paid media purchase")
+	}
+	if purchase.From == nil || purchase.From.ID != 42 {
+		t.Errorf("expected purchase from user 42, got %v", purchase.From)
+	}
+	if purchase.PaidMediaPayload != "order-123" {
+		t.Errorf("expected payload order-123, got %s", purchase.PaidMediaPayload)
+	}
+}
+
+func TestMessageForwardOrigin(t *testing.T) {
+	var message Message
+	err := json.Unmarshal([]byte(`{
+		"message_id": 1,
+		"date": 0,
+		"chat": {"id": 5},
+		"forward_origin": {
+			"type": "channel",
+			"date": 1700000000,
+			"chat": {"id": -100123, "type": "channel", "title": "News"},
+			"message_id": 42,
+			"author_signature": "Editor"
+		}
+	}`), &message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	origin := message.ForwardOrigin
+	if origin == nil || origin.Type != MessageOriginTypeChannel {
+		t.Fatalf("expected a channel origin, got %+v", origin)
+	}
+	if origin.Chat == nil || origin.Chat.Title != "News" || origin.MessageID != 42 {
+		t.Errorf("unexpected origin %+v", origin)
+	}
+
+	if message.ForwardFromChat == nil || message.ForwardFromChat.ID != -100123 {
+		t.Errorf("expected legacy forward chat -100123, got %v", message.ForwardFromChat)
+	}
+	if message.ForwardFromMessageID != 42 || message.ForwardSignature != "Editor" || message.ForwardDate != 1700000000 {
+		t.Errorf("unexpected legacy forward fields %d %q %d", message.ForwardFromMessageID, message.ForwardSignature, message.ForwardDate)
+	}
+}
+
+func TestMessagePassportData(t *testing.T) {
+	var message Message
+	err := json.Unmarshal([]byte(`{
+		"message_id": 1,
+		"date": 1700000000,
+		"chat": {"id": 7, "type": "private"},
+		"from": {"id": 7, "first_name": "User"},
+		"passport_data": {
+			"data": [
+				{
+					"type": "personal_details",
+					"data": "3jmfC2zL5DCfmn1LbnTnrfb9uNJNRCpPKxqDU4x3s8s=",
+					"hash": "u8bN0WkjM0RX4k6TCP3M1mzEvAn6DrBw4+3Gs9xP3jI="
+				},
+				{
+					"type": "passport",
+					"data": "b8BXp2zPBPdHlHBVnTDlBlzdZQqcjb9G6r3ZGvjE8hs=",
+					"front_side": {
+						"file_id": "DgADBAADyQADpiZwUdJfVS5PcQ84Ag",
+						"file_unique_id": "AgADyQADpiZwUQ",
+						"file_size": 71340,
+						"file_date": 1700000000
+					},
+					"selfie": {
+						"file_id": "DgADBAADygADpiZwUWt4s3Z4dCRbAg",
+						"file_unique_id": "AgADygADpiZwUQ",
+						"file_size": 68123,
+						"file_date": 1700000000
+					},
+					"hash": "Ah4nnT3PL2Hz0nRsv9Gy+OiHq5n3fn4iIo1jKd+ZpYE="
+				},
+				{
+					"type": "email",
+					"email": "user@example.com",
+					"hash": "x6uZcd2OQvnDfbDcL3I6oaFBlkJSNR0pLh4bqYAqW9s="
+				}
+			],
+			"credentials": {
+				"data": "f2e4NjqLWBkU3eXlY0cxUa9Q2+1HK3IXo8yPl1cfi7w=",
+				"hash": "SjeYdOGLyI4/2CO7A5nGsEkb/PfHxrslPNmW7p3r3Bk=",
+				"secret": "kKtNdqOjD3jlR+u3yt1Hg9wB5d2CxS0I0G1JlSaHEPE="
+			}
+		}
+	}`), &message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passport := message.PassportData
+	if passport == nil || len(passport.Data) != 3 {
+		t.Fatalf("expected passport data with 3 elements, got %+v", passport)
+	}
+
+	document := passport.Data[1]
+	if document.Type != "passport" || document.FrontSide == nil || document.FrontSide.FileSize != 71340 {
+		t.Errorf("unexpected passport element %+v", document)
+	}
+	if document.Selfie == nil || document.Hash == "" {
+		t.Errorf("expected selfie and hash on passport element %+v", document)
+	}
+	if passport.Data[2].Email != "user@example.com" {
+		t.Errorf("expected email element, got %+v", passport.Data[2])
+	}
+	if passport.Credentials == nil || passport.Credentials.Secret == "" {
+		t.Errorf("expected credentials, got %+v", passport.Credentials)
+	}
+}
+
+func TestMessageWebAppData(t *testing.T) {
+	var message Message
+	err := json.Unmarshal([]byte(`{
+		"message_id": 1,
+		"date": 0,
+		"chat": {"id": 7, "type": "private"},
+		"web_app_data": {"data": "{\"order\":42}", "button_text": "Order"}
+	}`), &message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message.WebAppData == nil || message.WebAppData.Data != `{"order":42}` || message.WebAppData.ButtonText != "Order" {
+		t.Errorf("unexpected web app data %+v", message.WebAppData)
+	}
+}
+
+func TestMessageWriteAccessAllowed(t *testing.T) {
+	var message Message
+	err := json.Unmarshal([]byte(`{
+		"message_id": 1,
+		"date": 0,
+		"chat": {"id": 42},
+		"write_access_allowed": {"from_request": true}
+	}`), &message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message.WriteAccessAllowed == nil {
+		t.Fatal("expected write_access_allowed to be decoded")
+	}
+	if !message.WriteAccessAllowed.FromRequest {
+		t.Error("expected FromRequest to be true")
+	}
+	if message.WriteAccessAllowed.FromAttachmentMenu {
+		t.Error("expected FromAttachmentMenu to be false")
+	}
+}
+
+func TestChatTypePredicates(t *testing.T) {
+	tests := []struct {
+		chatType string
+		expected string
+	}{
+		{ChatTypePrivate, "private"},
+		{ChatTypeGroup, "group"},
+		{ChatTypeSuperGroup, "supergroup"},
+		{ChatTypeChannel, "channel"},
+	}
+
+	for _, test := range tests {
+		chat := &Chat{Type: test.chatType}
+		predicates := map[string]bool{
+			"private":    chat.IsPrivate(),
+			"group":      chat.IsGroup(),
+			"supergroup": chat.IsSuperGroup(),
+			"channel":    chat.IsChannel(),
+		}
+
+		for name, value := range predicates {
+			if value != (name == test.expected) {
+				t.Errorf("chat type %q: expected %s predicate to be %t", test.chatType, name, name == test.expected)
+			}
+		}
+	}
+}
+
+func TestMessageAge(t *testing.T) {
+	message := Message{Date: int(time.Now().Add(-2 * time.Minute).Unix())}
+
+	if !message.IsOlderThan(time.Minute) {
+		t.Error("expected message to be older than a minute")
+	}
+	if message.IsOlderThan(5 * time.Minute) {
+		t.Error("expected message not to be older than five minutes")
+	}
+}
+
+func TestMessageBusinessFields(t *testing.T) {
+	var message Message
+	err := json.Unmarshal([]byte(`{
+		"message_id": 1,
+		"date": 0,
+		"business_connection_id": "conn-1",
+		"chat": {"id": 42, "type": "private"},
+		"sender_business_bot": {"id": 777, "is_bot": true, "first_name": "Assistant"},
+		"text": "hello"
+	}`), &message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message.SenderBusinessBot == nil || message.SenderBusinessBot.ID != 777 {
+		t.Errorf("expected sender business bot 777, got %v", message.SenderBusinessBot)
+	}
+	if message.BusinessConnectionID != "conn-1" {
+		t.Errorf("expected business connection conn-1, got %q", message.BusinessConnectionID)
+	}
+}
+
+func TestMessageMediaType(t *testing.T) {
+	tests := []struct {
+		message   string
+		mediaType MediaType
+		fileID    string
+	}{
+		{`{"animation": {"file_id": "animation"}, "document": {"file_id": "animation"}}`, MediaAnimation, "animation"},
+		{`{"audio": {"file_id": "audio"}}`, MediaAudio, "audio"},
+		{`{"document": {"file_id": "document"}}`, MediaDocument, "document"},
+		{`{"photo": [{"file_id": "small"}, {"file_id": "large"}]}`, MediaPhoto, "large"},
+		{`{"sticker": {"file_id": "sticker"}}`, MediaSticker, "sticker"},
+		{`{"video": {"file_id": "video"}}`, MediaVideo, "video"},
+		{`{"video_note": {"file_id": "video_note"}}`, MediaVideoNote, "video_note"},
+		{`{"voice": {"file_id": "voice"}}`, MediaVoice, "voice"},
+		{`{"text": "hello"}`, MediaText, ""},
+		{`{}`, MediaNone, ""},
+	}
+
+	for _, test := range tests {
+		var message Message
+		if err := json.Unmarshal([]byte(test.message), &message); err != nil {
+			t.Fatal(err)
+		}
+
+		if mediaType := message.MediaType(); mediaType != test.mediaType {
+			t.Errorf("%s: expected media type %q, got %q", test.message, test.mediaType, mediaType)
+		}
+
+		fileID, ok := message.FileID()
+		if fileID != test.fileID || ok != (test.fileID != "") {
+			t.Errorf("%s: expected file ID %q, got %q (%t)", test.message, test.fileID, fileID, ok)
+		}
+	}
+}
+
+func TestMediaMessageUpdates(t *testing.T) {
+	var updates []Update
+	err := json.Unmarshal([]byte(`[
+		{
+			"update_id": 10001,
+			"message": {
+				"message_id": 51,
+				"from": {"id": 7, "is_bot": false, "first_name": "Ann", "language_code": "en"},
+				"chat": {"id": 7, "first_name": "Ann", "type": "private"},
+				"date": 1700000000,
+				"photo": [
+					{"file_id": "AgACAgIAAxkBAAMzZ-small", "file_unique_id": "AQADsmall", "file_size": 1311, "width": 90, "height": 67},
+					{"file_id": "AgACAgIAAxkBAAMzZ-medium", "file_unique_id": "AQADmedium", "file_size": 17042, "width": 320, "height": 240},
+					{"file_id": "AgACAgIAAxkBAAMzZ-large", "file_unique_id": "AQADlarge", "file_size": 79230, "width": 1280, "height": 960}
+				],
+				"caption": "Holiday"
+			}
+		},
+		{
+			"update_id": 10002,
+			"message": {
+				"message_id": 52,
+				"from": {"id": 7, "is_bot": false, "first_name": "Ann"},
+				"chat": {"id": 7, "first_name": "Ann", "type": "private"},
+				"date": 1700000060,
+				"document": {
+					"file_name": "report.pdf",
+					"mime_type": "application/pdf",
+					"thumbnail": {"file_id": "AAMCAgADGQEAAzR-thumb", "file_unique_id": "AQADthumb", "file_size": 8312, "width": 226, "height": 320},
+					"file_id": "BQACAgIAAxkBAAM0Z-document",
+					"file_unique_id": "AgADdocument",
+					"file_size": 482317
+				}
+			}
+		}
+	]`), &updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	photo := updates[0].Message
+	if photo.MediaType() != MediaPhoto || len(photo.Photo) != 3 || photo.Caption != "Holiday" {
+		t.Fatalf("unexpected photo message %+v", photo)
+	}
+	if largest := photo.Photo[2]; largest.Width != 1280 || largest.FileSize != 79230 {
+		t.Errorf("unexpected largest photo size %+v", largest)
+	}
+	if fileID, _ := photo.FileID(); fileID != "AgACAgIAAxkBAAMzZ-large" {
+		t.Errorf("expected the largest photo to be downloaded, got %s", fileID)
+	}
+
+	document := updates[1].Message.Document
+	if document == nil || document.FileName != "report.pdf" || document.MimeType != "application/pdf" || document.FileSize != 482317 {
+		t.Fatalf("unexpected document %+v", document)
+	}
+	if document.Thumbnail == nil || document.Thumbnail.Height != 320 {
+		t.Errorf("unexpected document thumbnail %+v", document.Thumbnail)
+	}
+	if fileID, _ := updates[1].Message.FileID(); fileID != document.FileID {
+		t.Errorf("expected document file ID, got %s", fileID)
+	}
+}
+
+func TestMessageContact(t *testing.T) {
+	var message Message
+	err := json.Unmarshal([]byte(`{
+		"message_id": 1,
+		"date": 0,
+		"chat": {"id": 7, "type": "private"},
+		"from": {"id": 7, "first_name": "Ann"},
+		"contact": {"phone_number": "+15550100", "first_name": "Ann", "last_name": "Lee", "user_id": 7, "vcard": "BEGIN:VCARD\nEND:VCARD"}
+	}`), &message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contact := message.Contact
+	if contact == nil || contact.PhoneNumber != "+15550100" || contact.LastName != "Lee" {
+		t.Fatalf("unexpected contact %+v", contact)
+	}
+	if contact.UserID != message.From.ID {
+		t.Errorf("expected the sender's own contact, got user %d", contact.UserID)
+	}
+	if contact.VCard != "BEGIN:VCARD\nEND:VCARD" {
+		t.Errorf("unexpected vCard %q", contact.VCard)
+	}
+}
+
+func TestMessageForumTopicCreated(t *testing.T) {
+	var message Message
+	err := json.Unmarshal([]byte(`{
+		"message_id": 1,
+		"date": 0,
+		"chat": {"id": -100123, "type": "supergroup"},
+		"forum_topic_created": {"name": "Releases", "icon_color": 7322096}
+	}`), &message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message.ForumTopicCreated == nil || message.ForumTopicCreated.Name != "Releases" {
+		t.Errorf("expected topic Releases to be created, got %+v", message.ForumTopicCreated)
+	}
+	if message.ForumTopicClosed != nil {
+		t.Error("expected no forum_topic_closed")
+	}
+}
@@ -2,10 +2,13 @@ package tgapimanager
 
 import "fmt"
 
+// Wrap adds msg to err. The result still unwraps to err, so an *Error can be
+// recovered with AsAPIError or errors.As.
 func Wrap(msg string, err error) error {
 	return fmt.Errorf("%s: %w", msg, err)
 }
 
+// WrapIfError is the same as Wrap, but returns nil if err is nil.
 func WrapIfError(msg string, err error) error {
 	if err == nil {
 		return nil